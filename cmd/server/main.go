@@ -1,7 +1,66 @@
 package main
 
-import "github.com/go-park-mail-ru/2025_2_MindLeak/internal/server"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/config"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/server"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/tracing"
+)
 
 func main() {
-	server.StartServer()
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logger:", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(log)
+	log.Info("effective config", "config", cfg.String())
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Error("startup failed", "error", err)
+		os.Exit(1)
+	}
+
+	srv, err := server.New(cfg, log)
+	if err != nil {
+		log.Error("startup failed", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runErr := srv.Run(ctx)
+
+	// flushed after the server has drained so spans of in-flight requests are exported too
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Error("flush traces", "error", err)
+	}
+	cancel()
+
+	if runErr != nil {
+		log.Error("server stopped with error", "error", runErr)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/google/uuid"
+)
+
+// ErrUnauthorized is the single 401 every authenticated route returns, so
+// clients can't tell a missing cookie from an expired session.
+var ErrUnauthorized = apperror.New(apperror.CodeUnauthorized, "authentication required")
+
+var ErrForbidden = apperror.New(apperror.CodeForbidden, "permission denied")
+
+// Principal is whoever sent the request. Anonymous visitors still have a
+// session; User is set only once they have logged in.
+type Principal struct {
+	SessionID uuid.UUID
+	User      *user.User
+}
+
+func (p *Principal) Authenticated() bool {
+	return p != nil && p.User != nil
+}
+
+func (p *Principal) Can(permission rbac.Permission) bool {
+	return p.Authenticated() && rbac.Can(p.User.Roles, permission)
+}
+
+// Require is the permission check for handlers: ErrUnauthorized for
+// anonymous requests, ErrForbidden when the user lacks the permission.
+func Require(ctx context.Context, permission rbac.Permission) error {
+	p := FromContext(ctx)
+	if !p.Authenticated() {
+		return ErrUnauthorized
+	}
+	if !p.Can(permission) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the principal stored by the auth middleware, or nil if
+// the request carried no valid session.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
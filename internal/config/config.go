@@ -0,0 +1,549 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "MINDLEAK_"
+
+// Secret hides its value whenever the config is printed.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Cookie    CookieConfig    `yaml:"cookie"`
+	CSRF      CSRFConfig      `yaml:"csrf"`
+	CORS      CORSConfig      `yaml:"cors"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	OpenAPI   OpenAPIConfig   `yaml:"openapi"`
+	Admin     AdminConfig     `yaml:"admin"`
+}
+
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DrainDelay is how long /readyz fails before the listener closes, so
+	// load balancers notice and stop sending new requests.
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// TrustedProxies may set X-Real-IP; addresses or CIDR networks.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// MetricsAddr serves /metrics apart from the API, so the public port
+	// doesn't expose it; empty turns metrics off.
+	MetricsAddr string `yaml:"metrics_addr"`
+}
+
+type CookieConfig struct {
+	Name       string        `yaml:"name"`
+	TTL        time.Duration `yaml:"ttl"`
+	Secure     bool          `yaml:"secure"`
+	SameSite   string        `yaml:"same_site"`
+	Domain     string        `yaml:"domain"`
+	HostPrefix bool          `yaml:"host_prefix"`
+	Keys       []Secret      `yaml:"keys"`
+	KeysFile   string        `yaml:"keys_file"`
+}
+
+type CSRFConfig struct {
+	Secret     Secret `yaml:"secret"`
+	SecretFile string `yaml:"secret_file"`
+}
+
+// CORSConfig.AllowedMethods defaults to the methods of the registered
+// routes, so a new method doesn't need a config change.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	MaxAge           int      `yaml:"max_age"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+// RateLimitConfig limits login and registration per client IP and the feed
+// and reports per user.
+type RateLimitConfig struct {
+	Login        Limit `yaml:"login"`
+	Registration Limit `yaml:"registration"`
+	Feed         Limit `yaml:"feed"`
+	Report       Limit `yaml:"report"`
+}
+
+// Limit allows Requests per Window, refilled evenly.
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+type LogConfig struct {
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+type TracingConfig struct {
+	ServiceName string  `yaml:"service_name"`
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// OpenAPIConfig.Validate checks every request and response against the API
+// document; it buffers responses, so keep it off in production.
+type OpenAPIConfig struct {
+	Validate bool `yaml:"validate"`
+}
+
+// AdminConfig bootstraps the first admin on startup: the account is created
+// if it doesn't exist and granted the admin role either way.
+type AdminConfig struct {
+	Email        string `yaml:"email"`
+	Password     Secret `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8090",
+			MetricsAddr:     ":9091",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		Cookie: CookieConfig{
+			Name:     "session_id",
+			TTL:      60 * time.Minute,
+			SameSite: "lax",
+		},
+		CORS: CORSConfig{
+			// local frontends only; deployments add their site's origin
+			AllowedOrigins: []string{
+				"http://localhost:3000",
+				"http://localhost:5173",
+				"http://127.0.0.1:3000",
+			},
+			AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-Token"},
+			ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-CSRF-Token"},
+			MaxAge:           600,
+			AllowCredentials: true,
+		},
+		RateLimit: RateLimitConfig{
+			Login:        Limit{Requests: 10, Window: time.Minute},
+			Registration: Limit{Requests: 5, Window: time.Minute},
+			Feed:         Limit{Requests: 60, Window: time.Minute},
+			Report:       Limit{Requests: 10, Window: time.Hour},
+		},
+		Log: LogConfig{
+			Format: "text",
+			Level:  "info",
+		},
+		Tracing: TracingConfig{
+			ServiceName: "mindleak-backend",
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// option binds one setting to the MINDLEAK_<KEY> variable and the -<key> flag,
+// e.g. server.addr is MINDLEAK_SERVER_ADDR and -server.addr.
+type option struct {
+	key   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var options = []option{
+	{"server.addr", "listen address", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"server.metrics_addr", "listen address of /metrics, empty to disable", func(c *Config, v string) error {
+		c.Server.MetricsAddr = v
+		return nil
+	}},
+	{"server.read_timeout", "request read timeout", durationSetter(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"server.write_timeout", "response write timeout", durationSetter(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"server.idle_timeout", "keep-alive idle timeout", durationSetter(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"server.shutdown_timeout", "time to drain in-flight requests on shutdown", durationSetter(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"server.drain_delay", "time readiness fails before the listener closes on shutdown", durationSetter(func(c *Config) *time.Duration { return &c.Server.DrainDelay })},
+	{"server.trusted_proxies", "comma-separated proxy addresses or networks trusted to set X-Real-IP", func(c *Config, v string) error {
+		c.Server.TrustedProxies = splitList(v)
+		return nil
+	}},
+	{"cookie.name", "session cookie name", func(c *Config, v string) error {
+		c.Cookie.Name = v
+		return nil
+	}},
+	{"cookie.ttl", "session cookie lifetime", durationSetter(func(c *Config) *time.Duration { return &c.Cookie.TTL })},
+	{"cookie.secure", "mark session cookie Secure", boolSetter(func(c *Config) *bool { return &c.Cookie.Secure })},
+	{"cookie.same_site", "session cookie SameSite: lax, strict or none", func(c *Config, v string) error {
+		c.Cookie.SameSite = v
+		return nil
+	}},
+	{"cookie.domain", "session cookie domain", func(c *Config, v string) error {
+		c.Cookie.Domain = v
+		return nil
+	}},
+	{"cookie.host_prefix", "use the __Host- cookie prefix", boolSetter(func(c *Config) *bool { return &c.Cookie.HostPrefix })},
+	{"cookie.keys", "comma-separated cookie signing keys, newest first", func(c *Config, v string) error {
+		c.Cookie.Keys = nil
+		for _, key := range splitList(v) {
+			c.Cookie.Keys = append(c.Cookie.Keys, Secret(key))
+		}
+		return nil
+	}},
+	{"cookie.keys_file", "file with cookie signing keys, one per line", func(c *Config, v string) error {
+		c.Cookie.KeysFile = v
+		return nil
+	}},
+	{"csrf.secret", "CSRF token signing secret", func(c *Config, v string) error {
+		c.CSRF.Secret = Secret(v)
+		return nil
+	}},
+	{"csrf.secret_file", "file with the CSRF token signing secret", func(c *Config, v string) error {
+		c.CSRF.SecretFile = v
+		return nil
+	}},
+	{"cors.allowed_origins", "comma-separated allowed origins", func(c *Config, v string) error {
+		c.CORS.AllowedOrigins = splitList(v)
+		return nil
+	}},
+	{"cors.allowed_methods", "comma-separated allowed methods, the routes' methods if empty", func(c *Config, v string) error {
+		c.CORS.AllowedMethods = splitList(v)
+		return nil
+	}},
+	{"rate_limit.login.requests", "login attempts per window and client IP", intSetter(func(c *Config) *int { return &c.RateLimit.Login.Requests })},
+	{"rate_limit.login.window", "login rate limit window", durationSetter(func(c *Config) *time.Duration { return &c.RateLimit.Login.Window })},
+	{"rate_limit.registration.requests", "registrations per window and client IP", intSetter(func(c *Config) *int { return &c.RateLimit.Registration.Requests })},
+	{"rate_limit.registration.window", "registration rate limit window", durationSetter(func(c *Config) *time.Duration { return &c.RateLimit.Registration.Window })},
+	{"rate_limit.feed.requests", "feed requests per window and user", intSetter(func(c *Config) *int { return &c.RateLimit.Feed.Requests })},
+	{"rate_limit.feed.window", "feed rate limit window", durationSetter(func(c *Config) *time.Duration { return &c.RateLimit.Feed.Window })},
+	{"rate_limit.report.requests", "reports per window and user", intSetter(func(c *Config) *int { return &c.RateLimit.Report.Requests })},
+	{"rate_limit.report.window", "report rate limit window", durationSetter(func(c *Config) *time.Duration { return &c.RateLimit.Report.Window })},
+	{"log.format", "log output format: json or text", func(c *Config, v string) error {
+		c.Log.Format = v
+		return nil
+	}},
+	{"log.level", "minimal log level: debug, info, warn or error", func(c *Config, v string) error {
+		c.Log.Level = v
+		return nil
+	}},
+	{"tracing.exporter", "trace exporter: none, stdout or otlp", func(c *Config, v string) error {
+		c.Tracing.Exporter = v
+		return nil
+	}},
+	{"tracing.endpoint", "OTLP/HTTP endpoint URL, e.g. http://collector:4318", func(c *Config, v string) error {
+		c.Tracing.Endpoint = v
+		return nil
+	}},
+	{"tracing.sample_ratio", "fraction of new traces to sample, 0..1", func(c *Config, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		c.Tracing.SampleRatio = ratio
+		return nil
+	}},
+	{"admin.email", "email of the admin account to bootstrap on startup", func(c *Config, v string) error {
+		c.Admin.Email = v
+		return nil
+	}},
+	{"admin.password", "password of the bootstrapped admin account", func(c *Config, v string) error {
+		c.Admin.Password = Secret(v)
+		return nil
+	}},
+	{"admin.password_file", "file with the password of the bootstrapped admin account", func(c *Config, v string) error {
+		c.Admin.PasswordFile = v
+		return nil
+	}},
+	{"openapi.validate", "validate requests and responses against the OpenAPI document", boolSetter(func(c *Config) *bool { return &c.OpenAPI.Validate })},
+}
+
+// Load builds the config from defaults, then the YAML file, then environment
+// variables, then command-line flags; later sources win.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to YAML config file (env "+envPrefix+"CONFIG)")
+	flagValues := make(map[string]*string, len(options))
+	for _, opt := range options {
+		flagValues[opt.key] = fs.String(opt.key, "", opt.usage+" (env "+envName(opt.key)+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	path := *configPath
+	if path == "" {
+		path, _ = lookupEnv(envPrefix + "CONFIG")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range options {
+		if value, ok := lookupEnv(envName(opt.key)); ok {
+			if err := opt.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", envName(opt.key), err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if f.Name == opt.key && flagErr == nil {
+				if err := opt.set(cfg, *flagValues[opt.key]); err != nil {
+					flagErr = fmt.Errorf("-%s: %w", opt.key, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.readSecretFiles(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) readSecretFiles() error {
+	if c.Cookie.KeysFile != "" {
+		data, err := os.ReadFile(c.Cookie.KeysFile)
+		if err != nil {
+			return fmt.Errorf("read cookie keys: %w", err)
+		}
+		c.Cookie.Keys = nil
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				c.Cookie.Keys = append(c.Cookie.Keys, Secret(line))
+			}
+		}
+	}
+
+	if c.CSRF.SecretFile != "" {
+		data, err := os.ReadFile(c.CSRF.SecretFile)
+		if err != nil {
+			return fmt.Errorf("read csrf secret: %w", err)
+		}
+		c.CSRF.Secret = Secret(strings.TrimSpace(string(data)))
+	}
+
+	if c.Admin.PasswordFile != "" {
+		data, err := os.ReadFile(c.Admin.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("read admin password: %w", err)
+		}
+		c.Admin.Password = Secret(strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr is required"))
+	}
+	if c.Server.MetricsAddr != "" && c.Server.MetricsAddr == c.Server.Addr {
+		errs = append(errs, errors.New("server.metrics_addr must differ from server.addr"))
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server timeouts must be positive"))
+	}
+	if c.Server.DrainDelay < 0 {
+		errs = append(errs, errors.New("server.drain_delay must not be negative"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParseAddr(proxy); err == nil {
+			continue
+		}
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies: invalid address or network %q", proxy))
+		}
+	}
+
+	if c.Cookie.Name == "" {
+		errs = append(errs, errors.New("cookie.name is required"))
+	}
+	if c.Cookie.TTL <= 0 {
+		errs = append(errs, errors.New("cookie.ttl must be positive"))
+	}
+	switch strings.ToLower(c.Cookie.SameSite) {
+	case "lax", "strict":
+	case "none":
+		if !c.Cookie.Secure {
+			errs = append(errs, errors.New("cookie.same_site=none requires cookie.secure"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("cookie.same_site must be lax, strict or none, got %q", c.Cookie.SameSite))
+	}
+	if c.Cookie.HostPrefix && (!c.Cookie.Secure || c.Cookie.Domain != "") {
+		errs = append(errs, errors.New("cookie.host_prefix requires cookie.secure and an empty cookie.domain"))
+	}
+	for _, key := range c.Cookie.Keys {
+		if len(key) < 32 {
+			errs = append(errs, errors.New("cookie.keys must be at least 32 characters"))
+			break
+		}
+	}
+
+	if c.CSRF.Secret != "" && len(c.CSRF.Secret) < 32 {
+		errs = append(errs, errors.New("csrf.secret must be at least 32 characters"))
+	}
+
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.max_age must not be negative"))
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" && c.CORS.AllowCredentials {
+			errs = append(errs, errors.New("cors.allowed_origins must not contain * when credentials are allowed"))
+		}
+	}
+
+	for _, limit := range []struct {
+		name string
+		Limit
+	}{
+		{"login", c.RateLimit.Login},
+		{"registration", c.RateLimit.Registration},
+		{"feed", c.RateLimit.Feed},
+		{"report", c.RateLimit.Report},
+	} {
+		if limit.Requests <= 0 || limit.Window <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limit.%s needs positive requests and window", limit.name))
+		}
+	}
+
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("log.format must be json or text, got %q", c.Log.Format))
+	}
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+
+	switch strings.ToLower(c.Tracing.Exporter) {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	if c.Admin.Email != "" && len(c.Admin.Password) < 8 {
+		errs = append(errs, errors.New("admin.password must be at least 8 characters when admin.email is set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// String renders the effective config as YAML with secrets redacted.
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func intSetter(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolSetter(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
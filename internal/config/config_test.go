@@ -0,0 +1,220 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func envFrom(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	key := strings.Repeat("k", 32)
+
+	tests := []struct {
+		name    string
+		args    func(t *testing.T) []string
+		env     func(t *testing.T) map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":8090", cfg.Server.Addr)
+				assert.Equal(t, 10*time.Second, cfg.Server.ReadTimeout)
+				assert.Equal(t, time.Hour, cfg.Cookie.TTL)
+				assert.Equal(t, []string{"http://localhost:3000", "http://localhost:5173", "http://127.0.0.1:3000"}, cfg.CORS.AllowedOrigins)
+				assert.Empty(t, cfg.CORS.AllowedMethods, "derived from the routes")
+				assert.Equal(t, Limit{Requests: 10, Window: time.Minute}, cfg.RateLimit.Login)
+			},
+		},
+		{
+			name: "file overrides defaults",
+			args: func(t *testing.T) []string {
+				return []string{"-config", writeFile(t, "config.yaml", "server:\n  addr: \":9000\"\n  read_timeout: 5s\n")}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":9000", cfg.Server.Addr)
+				assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+				assert.Equal(t, 10*time.Second, cfg.Server.WriteTimeout)
+			},
+		},
+		{
+			name: "env overrides file",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"MINDLEAK_CONFIG":      writeFile(t, "config.yaml", "server:\n  addr: \":9000\"\n"),
+					"MINDLEAK_SERVER_ADDR": ":9001",
+				}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":9001", cfg.Server.Addr)
+			},
+		},
+		{
+			name: "flags override env",
+			args: func(t *testing.T) []string { return []string{"-server.addr", ":9002"} },
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"MINDLEAK_SERVER_ADDR": ":9001"}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":9002", cfg.Server.Addr)
+			},
+		},
+		{
+			name: "list values from env",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"MINDLEAK_CORS_ALLOWED_ORIGINS": "https://a.ru, https://*.b.ru"}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"https://a.ru", "https://*.b.ru"}, cfg.CORS.AllowedOrigins)
+			},
+		},
+		{
+			name: "secrets from files",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"MINDLEAK_COOKIE_KEYS_FILE": writeFile(t, "keys", key+"\n\n"+strings.Repeat("o", 32)+"\n"),
+					"MINDLEAK_CSRF_SECRET_FILE": writeFile(t, "csrf", key+"\n"),
+				}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []Secret{Secret(key), Secret(strings.Repeat("o", 32))}, cfg.Cookie.Keys)
+				assert.Equal(t, Secret(key), cfg.CSRF.Secret)
+			},
+		},
+		{
+			name: "admin bootstrap from env and password file",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"MINDLEAK_ADMIN_EMAIL":         "admin@mindleak.ru",
+					"MINDLEAK_ADMIN_PASSWORD_FILE": writeFile(t, "admin", "correct-horse\n"),
+				}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "admin@mindleak.ru", cfg.Admin.Email)
+				assert.Equal(t, Secret("correct-horse"), cfg.Admin.Password)
+			},
+		},
+		{
+			name:    "admin email without password",
+			args:    func(t *testing.T) []string { return []string{"-admin.email", "admin@mindleak.ru"} },
+			wantErr: "admin.password must be at least 8 characters",
+		},
+		{
+			name: "unknown field in file",
+			args: func(t *testing.T) []string {
+				return []string{"-config", writeFile(t, "config.yaml", "server:\n  port: 9000\n")}
+			},
+			wantErr: "field port not found",
+		},
+		{
+			name:    "invalid duration",
+			args:    func(t *testing.T) []string { return []string{"-server.read_timeout", "soon"} },
+			wantErr: "-server.read_timeout",
+		},
+		{
+			name: "validation errors",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"MINDLEAK_COOKIE_SAME_SITE": "none",
+					"MINDLEAK_COOKIE_KEYS":      "short",
+				}
+			},
+			wantErr: "cookie.same_site=none requires cookie.secure\ncookie.keys must be at least 32 characters",
+		},
+		{
+			name: "cookie name and rate limits from env",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"MINDLEAK_COOKIE_NAME":               "sid",
+					"MINDLEAK_RATE_LIMIT_LOGIN_REQUESTS": "3",
+					"MINDLEAK_RATE_LIMIT_REPORT_WINDOW":  "24h",
+					"MINDLEAK_CORS_ALLOWED_METHODS":      "GET, POST",
+				}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "sid", cfg.Cookie.Name)
+				assert.Equal(t, Limit{Requests: 3, Window: time.Minute}, cfg.RateLimit.Login)
+				assert.Equal(t, Limit{Requests: 10, Window: 24 * time.Hour}, cfg.RateLimit.Report)
+				assert.Equal(t, []string{"GET", "POST"}, cfg.CORS.AllowedMethods)
+			},
+		},
+		{
+			name:    "empty rate limit window",
+			args:    func(t *testing.T) []string { return []string{"-rate_limit.feed.window", "0s"} },
+			wantErr: "rate_limit.feed needs positive requests and window",
+		},
+		{
+			name: "trusted proxies",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"MINDLEAK_SERVER_TRUSTED_PROXIES": "172.16.0.0/12, 10.0.0.5"}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"172.16.0.0/12", "10.0.0.5"}, cfg.Server.TrustedProxies)
+			},
+		},
+		{
+			name:    "invalid trusted proxy",
+			args:    func(t *testing.T) []string { return []string{"-server.trusted_proxies", "nginx"} },
+			wantErr: `server.trusted_proxies: invalid address or network "nginx"`,
+		},
+		{
+			name: "missing config file",
+			args: func(t *testing.T) []string {
+				return []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}
+			},
+			wantErr: "read config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			if tt.args != nil {
+				args = tt.args(t)
+			}
+			env := map[string]string{}
+			if tt.env != nil {
+				env = tt.env(t)
+			}
+
+			cfg, err := Load(args, envFrom(env))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Cookie.Keys = []Secret{"cookie-signing-key-that-is-long-enough"}
+	cfg.CSRF.Secret = "csrf-signing-key-that-is-long-enough"
+
+	out := cfg.String()
+
+	assert.NotContains(t, out, "cookie-signing-key")
+	assert.NotContains(t, out, "csrf-signing-key")
+	assert.Contains(t, out, "[REDACTED]")
+	assert.Contains(t, out, "addr: :8090")
+}
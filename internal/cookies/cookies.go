@@ -1,40 +1,165 @@
 package cookies
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
-	"github.com/google/uuid"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const SessionID = "session_id"
 
-func SetCookie(w http.ResponseWriter, sessionId uuid.UUID) {
-	cookie := &http.Cookie{
+const hostPrefix = "__Host-"
+
+var (
+	ErrCookieNotFound = errors.New("cookie not found")
+	ErrInvalidCookie  = errors.New("invalid cookie")
+)
+
+// Config describes how the session cookie is written. Keys[0] signs new
+// cookies, every key is accepted on read, so a key can be rotated by
+// prepending the new one and dropping the old one after a TTL.
+type Config struct {
+	Name       string
+	Keys       [][]byte
+	TTL        time.Duration
+	Secure     bool
+	SameSite   http.SameSite
+	Domain     string
+	HostPrefix bool
+}
+
+var current atomic.Pointer[Config]
+
+func init() {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	current.Store(&Config{
 		Name:     SessionID,
-		Value:    sessionId.String(),
-		HttpOnly: true,
-		Secure:   false,
-		Expires:  time.Now().Add(60 * time.Minute),
-		Path:     "/",
+		Keys:     [][]byte{key},
+		TTL:      60 * time.Minute,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func Configure(cfg Config) error {
+	if cfg.Name == "" {
+		cfg.Name = SessionID
+	}
+	if len(cfg.Keys) == 0 {
+		return errors.New("at least one cookie signing key is required")
+	}
+	for _, key := range cfg.Keys {
+		if len(key) < 32 {
+			return errors.New("cookie signing keys must be at least 32 bytes")
+		}
+	}
+	if cfg.TTL <= 0 {
+		return errors.New("cookie ttl must be positive")
 	}
+	if cfg.SameSite == http.SameSiteNoneMode && !cfg.Secure {
+		return errors.New("SameSite=None cookies must be secure")
+	}
+	if cfg.HostPrefix && (!cfg.Secure || cfg.Domain != "") {
+		return errors.New("__Host- cookies must be secure and have no domain")
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	current.Store(&cfg)
+	return nil
+}
+
+func Name() string {
+	cfg := current.Load()
+	if cfg.HostPrefix {
+		return hostPrefix + cfg.Name
+	}
+	return cfg.Name
+}
+
+func SetCookie(w http.ResponseWriter, sessionId uuid.UUID) {
+	cfg := current.Load()
+	cookie := newCookie(cfg)
+	cookie.Value = Encode(sessionId.String())
+	cookie.Expires = time.Now().Add(cfg.TTL)
+	cookie.MaxAge = int(cfg.TTL.Seconds())
 	http.SetCookie(w, cookie)
 }
 
+// GetCookie returns the session cookie with its signature verified and
+// stripped, so callers only ever see a value this server issued.
 func GetCookie(r *http.Request) (*http.Cookie, error) {
-	cookie, err := r.Cookie(SessionID)
+	cookie, err := r.Cookie(Name())
+	if err != nil {
+		return nil, ErrCookieNotFound
+	}
+	value, err := Decode(cookie.Value)
 	if err != nil {
-		return nil, errors.New("cookie not found")
+		return nil, err
 	}
+	cookie.Value = value
 	return cookie, nil
 }
 
 func DeleteCookie(w http.ResponseWriter, r *http.Request) error {
-	cookie, err := r.Cookie(SessionID)
-	if err != nil {
-		return errors.New("cookie not found")
+	if _, err := r.Cookie(Name()); err != nil {
+		return ErrCookieNotFound
 	}
+	cookie := newCookie(current.Load())
+	cookie.Expires = time.Unix(0, 0)
 	cookie.MaxAge = -1
 	http.SetCookie(w, cookie)
 	return nil
 }
+
+func Encode(value string) string {
+	cfg := current.Load()
+	return value + "." + sign(cfg.Keys[0], Name(), value)
+}
+
+func Decode(signed string) (string, error) {
+	idx := strings.LastIndexByte(signed, '.')
+	if idx <= 0 {
+		return "", ErrInvalidCookie
+	}
+	value, signature := signed[:idx], signed[idx+1:]
+
+	for _, key := range current.Load().Keys {
+		if hmac.Equal([]byte(signature), []byte(sign(key, Name(), value))) {
+			return value, nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+func newCookie(cfg *Config) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     Name(),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   cfg.Secure,
+		SameSite: cfg.SameSite,
+	}
+	if !cfg.HostPrefix {
+		cookie.Domain = cfg.Domain
+	}
+	return cookie
+}
+
+// sign binds the value to the cookie name so a signed value can't be replayed under another cookie.
+func sign(key []byte, name, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "=" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -3,7 +3,9 @@ package cookies
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,22 @@ func TestCookies(t *testing.T) {
 		},
 		{
 			name: "GetCookie returns existing cookie",
+			setupReq: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.AddCookie(&http.Cookie{Name: SessionID, Value: Encode(sessionID.String())})
+				return r
+			},
+		},
+		{
+			name: "GetCookie tampered returns error",
+			setupReq: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.AddCookie(&http.Cookie{Name: SessionID, Value: uuid.NewString() + "." + strings.Split(Encode(sessionID.String()), ".")[1]})
+				return r
+			},
+		},
+		{
+			name: "GetCookie unsigned returns error",
 			setupReq: func() *http.Request {
 				r := httptest.NewRequest(http.MethodGet, "/", nil)
 				r.AddCookie(&http.Cookie{Name: SessionID, Value: sessionID.String()})
@@ -66,9 +84,10 @@ func TestCookies(t *testing.T) {
 
 				c := cookies[0]
 				assert.Equal(t, SessionID, c.Name)
-				assert.Equal(t, sessionID.String(), c.Value)
+				assert.Equal(t, Encode(sessionID.String()), c.Value)
 				assert.True(t, c.HttpOnly)
 				assert.False(t, c.Secure)
+				assert.Equal(t, http.SameSiteLaxMode, c.SameSite)
 				assert.Equal(t, "/", c.Path)
 
 			case "GetCookie returns existing cookie":
@@ -77,6 +96,11 @@ func TestCookies(t *testing.T) {
 				assert.NotNil(t, c)
 				assert.Equal(t, sessionID.String(), c.Value)
 
+			case "GetCookie tampered returns error", "GetCookie unsigned returns error":
+				c, err := GetCookie(req)
+				assert.Nil(t, c)
+				assert.ErrorIs(t, err, ErrInvalidCookie)
+
 			case "GetCookie missing returns error":
 				c, err := GetCookie(req)
 				assert.Nil(t, c)
@@ -93,6 +117,9 @@ func TestCookies(t *testing.T) {
 				c := cookies[0]
 				assert.Equal(t, SessionID, c.Name)
 				assert.Equal(t, -1, c.MaxAge)
+				assert.Empty(t, c.Value)
+				assert.Equal(t, "/", c.Path)
+				assert.True(t, c.HttpOnly)
 
 			case "DeleteCookie missing returns error":
 				err := DeleteCookie(w, req)
@@ -101,3 +128,75 @@ func TestCookies(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigure(t *testing.T) {
+	defaults := *current.Load()
+	t.Cleanup(func() { current.Store(&defaults) })
+
+	oldKey := []byte(strings.Repeat("o", 32))
+	newKey := []byte(strings.Repeat("n", 32))
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name:    "no keys",
+			cfg:     Config{TTL: time.Hour},
+			wantErr: "at least one cookie signing key is required",
+		},
+		{
+			name:    "short key",
+			cfg:     Config{Keys: [][]byte{[]byte("short")}, TTL: time.Hour},
+			wantErr: "cookie signing keys must be at least 32 bytes",
+		},
+		{
+			name:    "SameSite=None without Secure",
+			cfg:     Config{Keys: [][]byte{newKey}, TTL: time.Hour, SameSite: http.SameSiteNoneMode},
+			wantErr: "SameSite=None cookies must be secure",
+		},
+		{
+			name:    "__Host- prefix with domain",
+			cfg:     Config{Keys: [][]byte{newKey}, TTL: time.Hour, Secure: true, HostPrefix: true, Domain: "example.com"},
+			wantErr: "__Host- cookies must be secure and have no domain",
+		},
+		{
+			name: "__Host- prefix",
+			cfg:  Config{Keys: [][]byte{newKey}, TTL: time.Hour, Secure: true, HostPrefix: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Configure(tt.cfg)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			SetCookie(w, uuid.New())
+			c := w.Result().Cookies()[0]
+			assert.Equal(t, "__Host-session_id", c.Name)
+			assert.True(t, c.Secure)
+			assert.Empty(t, c.Domain)
+		})
+	}
+
+	t.Run("key rotation", func(t *testing.T) {
+		assert.NoError(t, Configure(Config{Keys: [][]byte{oldKey}, TTL: time.Hour}))
+		signed := Encode("value")
+
+		assert.NoError(t, Configure(Config{Keys: [][]byte{newKey, oldKey}, TTL: time.Hour}))
+		value, err := Decode(signed)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.NotEqual(t, signed, Encode("value"))
+
+		assert.NoError(t, Configure(Config{Keys: [][]byte{newKey}, TTL: time.Hour}))
+		_, err = Decode(signed)
+		assert.ErrorIs(t, err, ErrInvalidCookie)
+	})
+}
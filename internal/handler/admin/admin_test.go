@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func TestRoleHandlers(t *testing.T) {
+	type test struct {
+		name          string
+		handler       func(http.ResponseWriter, *http.Request, user.UserRepository, audit.AuditLog)
+		target        func(admin, member *user.User) string
+		role          string
+		wantStatus    int
+		wantRoles     []rbac.Role
+		wantAudit     string
+		wantErrorText string
+	}
+
+	tests := []test{
+		{
+			name:       "grant",
+			handler:    GrantRoleHandler,
+			target:     func(_, member *user.User) string { return member.Id.String() },
+			role:       "moderator",
+			wantStatus: http.StatusOK,
+			wantRoles:  []rbac.Role{rbac.RoleUser, rbac.RoleAuthor, rbac.RoleModerator},
+			wantAudit:  audit.ActionRoleGrant,
+		},
+		{
+			name:          "grant unknown role",
+			handler:       GrantRoleHandler,
+			target:        func(_, member *user.User) string { return member.Id.String() },
+			role:          "root",
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: `unknown role "root"`,
+		},
+		{
+			name:          "grant to invalid id",
+			handler:       GrantRoleHandler,
+			target:        func(_, _ *user.User) string { return "42" },
+			role:          "author",
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "invalid user id",
+		},
+		{
+			name:          "grant to unknown user",
+			handler:       GrantRoleHandler,
+			target:        func(_, _ *user.User) string { return uuid.NewString() },
+			role:          "author",
+			wantStatus:    http.StatusNotFound,
+			wantErrorText: "user not found",
+		},
+		{
+			name:       "revoke",
+			handler:    RevokeRoleHandler,
+			target:     func(_, member *user.User) string { return member.Id.String() },
+			role:       "author",
+			wantStatus: http.StatusOK,
+			wantRoles:  []rbac.Role{rbac.RoleUser},
+			wantAudit:  audit.ActionRoleRevoke,
+		},
+		{
+			name:          "revoke user role",
+			handler:       RevokeRoleHandler,
+			target:        func(_, member *user.User) string { return member.Id.String() },
+			role:          "user",
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "the user role cannot be revoked",
+		},
+		{
+			name:          "revoke own admin role",
+			handler:       RevokeRoleHandler,
+			target:        func(admin, _ *user.User) string { return admin.Id.String() },
+			role:          "admin",
+			wantStatus:    http.StatusConflict,
+			wantErrorText: "cannot revoke your own admin role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := user.NewInMemoryUser()
+			admin, _ := users.CreateUser("admin@mail.ru", "password1", "Admin")
+			admin, _ = users.GrantRole(admin.Id, rbac.RoleAdmin)
+			member, _ := users.CreateUser("member@mail.ru", "password1", "Member")
+			_, _ = users.GrantRole(member.Id, rbac.RoleAuthor)
+
+			target := tt.target(admin, member)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/"+target+"/roles/"+tt.role, nil)
+			req.SetPathValue("id", target)
+			req.SetPathValue("role", tt.role)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{SessionID: uuid.New(), User: admin}))
+			w := httptest.NewRecorder()
+			auditLog := audit.NewInMemoryAuditLog()
+
+			tt.handler(w, req, users, auditLog)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				assert.Empty(t, auditLog.Entries)
+				return
+			}
+
+			var resp RolesResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, member.Id, resp.UserID)
+			assert.Equal(t, tt.wantRoles, resp.Roles)
+
+			stored, _ := users.GetUserById(member.Id)
+			assert.Equal(t, tt.wantRoles, stored.Roles)
+
+			if assert.Len(t, auditLog.Entries, 1) {
+				entry := auditLog.Entries[0]
+				assert.Equal(t, tt.wantAudit, entry.Action)
+				assert.Equal(t, admin.Id, entry.ActorId)
+				assert.Equal(t, member.Id, entry.TargetId)
+				assert.Equal(t, tt.role, entry.Note)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package article
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/visibility"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+// ArticleHandler serves both /articles/{id} and the permalink
+// /articles/{id}-{slug}. A slug the article had before a title change
+// redirects to the current permalink; the id alone is always enough.
+func ArticleHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository, users user.UserRepository,
+	relations relation.RelationRepository) {
+	articleID, slug, err := parsePermalink(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	found, err := articles.GetArticleById(articleID)
+	if err != nil {
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+
+	filter, err := visibility.NewFilter(auth.FromContext(r.Context()), users)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := filter.Personalize(relations); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	// answering 404 rather than 403 keeps shadow-bans undetectable
+	if !filter.CanSee(found) {
+		json.WriteError(w, http.StatusNotFound, "article not found")
+		return
+	}
+	if slug != "" && slug != found.Slug {
+		if !found.HasSlug(slug) {
+			json.WriteError(w, http.StatusNotFound, "article not found")
+			return
+		}
+		http.Redirect(w, r, permalink(r, found), http.StatusMovedPermanently)
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, found); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// parsePermalink splits "{id}-{slug}" into its parts; the slug is empty
+// when the segment is just the id.
+func parsePermalink(segment string) (uuid.UUID, string, error) {
+	id, slug := segment, ""
+	// a uuid is 36 characters, hyphens included
+	if len(segment) > 36 && segment[36] == '-' {
+		id, slug = segment[:36], segment[37:]
+	}
+	articleID, err := uuid.Parse(id)
+	return articleID, slug, err
+}
+
+// permalink is the request URL with its last segment replaced by the
+// article's current "{id}-{slug}".
+func permalink(r *http.Request, a *article.Article) string {
+	dir := r.URL.Path[:strings.LastIndexByte(r.URL.Path, '/')+1]
+	target := dir + a.Id.String() + "-" + a.Slug
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	return target
+}
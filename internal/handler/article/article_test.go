@@ -0,0 +1,104 @@
+package article
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ArticleResponse struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func TestArticleHandler(t *testing.T) {
+	users := user.NewInMemoryUser()
+	shadowBanned, _ := users.CreateUser("shadow@mail.ru", "password1", "Shadow")
+	_, _ = users.Restrict(shadowBanned.Id, user.Restriction{Kind: user.RestrictionShadowBan, Reason: "spam"})
+	articles := article.NewInMemoryArticle()
+	existing, err := articles.CreateArticle(article.Author{Id: uuid.New()}, "Заголовок", article.FormatMarkdown, "Текст", nil)
+	assert.NoError(t, err)
+	hidden, _ := articles.CreateArticle(article.Author{Id: uuid.New()}, "Скрытая", article.FormatMarkdown, "Текст", nil)
+	_, _ = articles.HideArticle(hidden.Id)
+	shadowed, _ := articles.CreateArticle(article.Author{Id: shadowBanned.Id}, "Тень", article.FormatMarkdown, "Текст", nil)
+	for _, a := range []*article.Article{existing, hidden, shadowed} {
+		_, _ = articles.SetStatus(a.Id, article.StatusPublished, nil)
+	}
+	draft, _ := articles.CreateArticle(article.Author{Id: shadowBanned.Id}, "Черновик", article.FormatMarkdown, "Текст", nil)
+	blocker, _ := users.CreateUser("blocker@mail.ru", "password1", "Blocker")
+	relations := relation.NewInMemoryRelation()
+	_ = relations.Block(blocker.Id, existing.AuthorId)
+	renamed, _ := articles.CreateArticle(article.Author{Id: uuid.New()}, "Старое название", article.FormatMarkdown, "Текст", nil)
+	_, _ = articles.SetStatus(renamed.Id, article.StatusPublished, nil)
+	renamed, _ = articles.UpdateArticle(renamed.Id, "Новое название", article.FormatMarkdown, "Текст", nil)
+	moderator := &auth.Principal{SessionID: uuid.New(), User: &user.User{Roles: []rbac.Role{rbac.RoleModerator}}}
+
+	tests := []struct {
+		name          string
+		id            string
+		principal     *auth.Principal
+		wantStatus    int
+		wantTitle     string
+		wantLocation  string
+		wantErrorText string
+	}{
+		{name: "found", id: existing.Id.String(), wantStatus: http.StatusOK, wantTitle: "Заголовок"},
+		{name: "not found", id: uuid.NewString(), wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "hidden", id: hidden.Id.String(), wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "hidden for moderator", id: hidden.Id.String(), principal: moderator, wantStatus: http.StatusOK, wantTitle: "Скрытая"},
+		{name: "shadow-banned author", id: shadowed.Id.String(), wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "shadow-banned author sees own article", id: shadowed.Id.String(), principal: &auth.Principal{User: shadowBanned}, wantStatus: http.StatusOK, wantTitle: "Тень"},
+		{name: "blocked author", id: existing.Id.String(), principal: &auth.Principal{User: blocker}, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "draft", id: draft.Id.String(), principal: moderator, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "own draft", id: draft.Id.String(), principal: &auth.Principal{User: shadowBanned}, wantStatus: http.StatusOK, wantTitle: "Черновик"},
+		{name: "invalid id", id: "not-a-uuid", wantStatus: http.StatusBadRequest, wantErrorText: "invalid article id"},
+		{name: "permalink", id: renamed.Id.String() + "-novoe-nazvanie", wantStatus: http.StatusOK, wantTitle: "Новое название"},
+		{name: "old slug redirects", id: renamed.Id.String() + "-staroe-nazvanie", wantStatus: http.StatusMovedPermanently, wantLocation: "/api/v1/articles/" + renamed.Id.String() + "-novoe-nazvanie"},
+		{name: "unknown slug", id: renamed.Id.String() + "-chuzhoe-nazvanie", wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "old slug of a hidden article", id: hidden.Id.String() + "-staroe", wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "invalid id with slug", id: "not-a-uuid-at-all-but-long-enough-to-split-novoe", wantStatus: http.StatusBadRequest, wantErrorText: "invalid article id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/articles/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			ArticleHandler(w, req, articles, users, relations)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantLocation != "" {
+				assert.Equal(t, tt.wantLocation, w.Header().Get("Location"))
+				return
+			}
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+
+			var resp ArticleResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.id[:36], resp.Id)
+			assert.Equal(t, tt.wantTitle, resp.Title)
+		})
+	}
+}
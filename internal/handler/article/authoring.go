@@ -0,0 +1,270 @@
+package article
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/markdown"
+	"github.com/google/uuid"
+)
+
+const (
+	maxTitleLength   = 200
+	maxContentLength = 100000
+)
+
+type ArticleInput struct {
+	Title      string             `json:"title"`
+	Format     article.Format     `json:"format"`
+	Content    string             `json:"content"`
+	Visibility article.Visibility `json:"visibility"`
+}
+
+type StatusInput struct {
+	Status    article.Status `json:"status"`
+	PublishAt *time.Time     `json:"publish_at"`
+}
+
+type VisibilityInput struct {
+	Visibility article.Visibility `json:"visibility"`
+}
+
+// CreateArticleHandler stores a draft as its first revision; it takes a
+// separate status change to publish it.
+func CreateArticleHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	input := new(ArticleInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if err := validateText(input.Title, input.Content); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if input.Format == "" {
+		input.Format = article.FormatMarkdown
+	}
+	if err := validateFormat(input.Format); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = article.VisibilityPublic
+	}
+	if _, err := article.ParseVisibility(string(input.Visibility)); err != nil {
+		json.WriteAppError(w, apperror.Validation("visibility", "must be one of public, unlisted, followers, private"))
+		return
+	}
+
+	author := auth.FromContext(r.Context()).User
+	created, err := articles.CreateArticle(article.Author{Id: author.Id, Name: author.Name, Avatar: author.Avatar}, input.Title, input.Format, input.Content,
+		func(saved *article.Article) error {
+			_, err := revisions.AddRevision(revision.Revision{
+				ArticleId: saved.Id, EditorId: author.Id, Title: saved.Title, Format: saved.Format, Content: saved.Content,
+				PlainText: saved.PlainText,
+			})
+			return err
+		})
+	if err != nil {
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+	if input.Visibility != created.Visibility {
+		if created, err = articles.SetVisibility(created.Id, input.Visibility); err != nil {
+			json.WriteAppError(w, err)
+			return
+		}
+	}
+
+	if err := json.Write(w, http.StatusCreated, created); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// MyArticlesHandler lists every article of the current user, drafts included.
+func MyArticlesHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository) {
+	author := auth.FromContext(r.Context()).User
+	own, err := articles.GetArticlesByAuthorId(author.Id)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if own == nil {
+		own = make([]*article.Article, 0)
+	}
+
+	if err := json.Write(w, http.StatusOK, own); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// SetStatusHandler moves an article along draft → scheduled → published →
+// archived. Scheduling and publishing need the article.publish permission.
+func SetStatusHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository) {
+	articleID, err := ownArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input := new(StatusInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if _, err := article.ParseStatus(string(input.Status)); err != nil {
+		json.WriteAppError(w, apperror.Validation("status", "must be one of draft, scheduled, published, archived"))
+		return
+	}
+	if input.Status == article.StatusScheduled || input.Status == article.StatusPublished {
+		if err := auth.Require(r.Context(), rbac.ArticlePublish); err != nil {
+			json.WriteAppError(w, err)
+			return
+		}
+	}
+
+	updated, err := articles.SetStatus(articleID, input.Status, input.PublishAt)
+	switch {
+	case errors.Is(err, article.ErrPublishAtNotInFuture):
+		json.WriteAppError(w, apperror.Validation("publish_at", err.Error()))
+		return
+	case errors.Is(err, article.ErrInvalidTransition):
+		json.WriteAppError(w, apperror.Wrap(apperror.CodeConflict, err.Error(), err))
+		return
+	case err != nil:
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, updated); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+func SetVisibilityHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository) {
+	articleID, err := ownArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input := new(VisibilityInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if _, err := article.ParseVisibility(string(input.Visibility)); err != nil {
+		json.WriteAppError(w, apperror.Validation("visibility", "must be one of public, unlisted, followers, private"))
+		return
+	}
+
+	updated, err := articles.SetVisibility(articleID, input.Visibility)
+	if err != nil {
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, updated); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// ConvertToBlocksHandler moves a Markdown or plain-text article to the block
+// format the editor works with. The conversion is saved as a new revision,
+// so restoring the previous one undoes it.
+func ConvertToBlocksHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	found, err := ownArticle(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if found.Format == article.FormatBlocks {
+		json.WriteError(w, http.StatusConflict, "article is already in the blocks format")
+		return
+	}
+
+	doc := markdown.ToBlocks(found.Content)
+	saveRevision(w, r, articles, revisions, found.Id, found.Title, article.FormatBlocks, doc.Marshal(), nil)
+}
+
+// DeleteArticleHandler deletes an own article. Its revisions are kept, so
+// moderators can still read the history.
+func DeleteArticleHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository) {
+	articleID, err := ownArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if _, err := articles.DeleteArticle(articleID); err != nil {
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, map[string]string{"message": "article deleted"}); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+func ownArticleID(r *http.Request, articles article.ArticleRepository) (uuid.UUID, error) {
+	found, err := ownArticle(r, articles)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return found.Id, nil
+}
+
+// ownArticle answers 404 for other people's articles, so drafts of other
+// authors can't be probed for.
+func ownArticle(r *http.Request, articles article.ArticleRepository) (*article.Article, error) {
+	articleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return nil, apperror.New(apperror.CodeBadRequest, "invalid article id")
+	}
+	found, err := articles.GetArticleById(articleID)
+	if err != nil {
+		return nil, articleError(err)
+	}
+	if found.AuthorId != auth.FromContext(r.Context()).User.Id {
+		return nil, articleError(article.ErrArticleNotFound)
+	}
+	return found, nil
+}
+
+func validateText(title, content string) error {
+	if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+		return apperror.Validation("title", "must be between 1 and 200 characters")
+	}
+	if strings.TrimSpace(content) == "" || utf8.RuneCountInString(content) > maxContentLength {
+		return apperror.Validation("content", "must be between 1 and 100000 characters")
+	}
+	return nil
+}
+
+func validateFormat(format article.Format) error {
+	if _, err := article.ParseFormat(string(format)); err != nil {
+		return apperror.Validation("format", "must be one of markdown, blocks")
+	}
+	return nil
+}
+
+func articleError(err error) error {
+	switch {
+	case errors.Is(err, article.ErrInvalidContent):
+		return apperror.Validation("content", strings.TrimPrefix(err.Error(), article.ErrInvalidContent.Error()+": "))
+	case errors.Is(err, article.ErrArticleNotFound):
+		return apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	case errors.Is(err, article.ErrDuplicateTitle):
+		return apperror.Wrap(apperror.CodeConflict, err.Error(), err)
+	}
+	return err
+}
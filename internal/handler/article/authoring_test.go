@@ -0,0 +1,257 @@
+package article
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthoringRequest(method, target, body string, principal *user.User) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{SessionID: uuid.New(), User: principal}))
+}
+
+func TestCreateArticleHandler(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Name: "Мария", Avatar: "https://example.com/maria.png", Roles: []rbac.Role{rbac.RoleUser}}
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatus     int
+		wantVisibility article.Visibility
+		wantErrorText  string
+	}{
+		{name: "draft", body: `{"title":"Заголовок","content":"Текст"}`, wantStatus: http.StatusCreated, wantVisibility: article.VisibilityPublic},
+		{name: "private draft", body: `{"title":"Заголовок","content":"Текст","visibility":"private"}`, wantStatus: http.StatusCreated, wantVisibility: article.VisibilityPrivate},
+		{name: "empty title", body: `{"title":"  ","content":"Текст"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be between 1 and 200 characters"},
+		{name: "empty content", body: `{"title":"Заголовок","content":""}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be between 1 and 100000 characters"},
+		{name: "unknown visibility", body: `{"title":"Заголовок","content":"Текст","visibility":"friends"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be one of public, unlisted, followers, private"},
+		{name: "blocks", body: `{"title":"Заголовок","format":"blocks","content":"{\"version\":1,\"blocks\":[{\"type\":\"paragraph\",\"data\":{\"text\":\"Текст\"}}]}"}`, wantStatus: http.StatusCreated, wantVisibility: article.VisibilityPublic},
+		{name: "invalid blocks", body: `{"title":"Заголовок","format":"blocks","content":"{\"version\":1,\"blocks\":[]}"}`, wantStatus: http.StatusBadRequest, wantErrorText: "invalid block document: at /blocks: minItems: got 0, want 1"},
+		{name: "unknown format", body: `{"title":"Заголовок","format":"html","content":"Текст"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be one of markdown, blocks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := article.NewInMemoryArticle()
+			revisions := revision.NewInMemoryRevision()
+			w := httptest.NewRecorder()
+
+			CreateArticleHandler(w, newAuthoringRequest(http.MethodPost, "/api/v1/articles", tt.body, author), articles, revisions)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+
+			var resp article.Article
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, article.StatusDraft, resp.Status)
+			assert.Equal(t, tt.wantVisibility, resp.Visibility)
+			assert.Equal(t, author.Name, resp.AuthorName)
+			assert.Equal(t, author.Avatar, resp.AuthorAvatar)
+			own, _ := articles.GetArticlesByAuthorId(author.Id)
+			assert.Len(t, own, 1)
+			history, _ := revisions.GetRevisions(resp.Id)
+			if assert.Len(t, history, 1) {
+				assert.Equal(t, author.Id, history[0].EditorId)
+				assert.Equal(t, resp.Content, history[0].Content)
+			}
+		})
+	}
+}
+
+func TestConvertToBlocksHandler(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	stranger := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+
+	tests := []struct {
+		name          string
+		principal     *user.User
+		format        article.Format
+		content       string
+		wantStatus    int
+		wantErrorText string
+	}{
+		{name: "markdown", principal: author, format: article.FormatMarkdown, content: "## Итоги\n\nРост на **30%**", wantStatus: http.StatusOK},
+		{name: "already blocks", principal: author, format: article.FormatBlocks, content: `{"version":1,"blocks":[{"type":"paragraph","data":{"text":"Текст"}}]}`, wantStatus: http.StatusConflict, wantErrorText: "article is already in the blocks format"},
+		{name: "someone else's article", principal: stranger, format: article.FormatMarkdown, content: "Текст", wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := article.NewInMemoryArticle()
+			revisions := revision.NewInMemoryRevision()
+			draft, err := articles.CreateArticle(article.Author{Id: author.Id}, "Черновик", tt.format, tt.content, nil)
+			assert.NoError(t, err)
+			req := newAuthoringRequest(http.MethodPost, "/api/v1/articles/"+draft.Id.String()+"/convert-to-blocks", "", tt.principal)
+			req.SetPathValue("id", draft.Id.String())
+			w := httptest.NewRecorder()
+
+			ConvertToBlocksHandler(w, req, articles, revisions)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+
+			var resp article.Article
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, article.FormatBlocks, resp.Format)
+			assert.Equal(t, `{"version":1,"blocks":[{"type":"header","data":{"text":"Итоги","level":2}},{"type":"paragraph","data":{"text":"Рост на <strong>30%</strong>"}}]}`, resp.Content)
+			assert.Equal(t, "<h2>Итоги</h2>\n<p>Рост на <strong>30%</strong></p>\n", resp.ContentHTML)
+			history, _ := revisions.GetRevisions(draft.Id)
+			if assert.Len(t, history, 1) {
+				assert.Equal(t, article.FormatBlocks, history[0].Format)
+			}
+		})
+	}
+}
+
+func TestSetStatusHandler(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleAuthor}}
+	writer := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		principal     *user.User
+		ownedBy       *user.User
+		body          string
+		wantStatus    int
+		wantArticle   article.Status
+		wantErrorText string
+	}{
+		{name: "publish", principal: author, ownedBy: author, body: `{"status":"published"}`, wantStatus: http.StatusOK, wantArticle: article.StatusPublished},
+		{name: "schedule", principal: author, ownedBy: author, body: `{"status":"scheduled","publish_at":"` + future + `"}`, wantStatus: http.StatusOK, wantArticle: article.StatusScheduled},
+		{name: "schedule in the past", principal: author, ownedBy: author, body: `{"status":"scheduled","publish_at":"2000-01-01T00:00:00Z"}`, wantStatus: http.StatusBadRequest, wantErrorText: "publish_at must be in the future"},
+		{name: "invalid transition", principal: author, ownedBy: author, body: `{"status":"archived"}`, wantStatus: http.StatusConflict, wantErrorText: "invalid status transition from draft to archived"},
+		{name: "unknown status", principal: author, ownedBy: author, body: `{"status":"deleted"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be one of draft, scheduled, published, archived"},
+		{name: "publish without permission", principal: writer, ownedBy: writer, body: `{"status":"published"}`, wantStatus: http.StatusForbidden, wantErrorText: "permission denied"},
+		{name: "someone else's article", principal: author, ownedBy: writer, body: `{"status":"published"}`, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := article.NewInMemoryArticle()
+			draft, _ := articles.CreateArticle(article.Author{Id: tt.ownedBy.Id}, "Черновик", article.FormatMarkdown, "Текст", nil)
+			req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+draft.Id.String()+"/status", tt.body, tt.principal)
+			req.SetPathValue("id", draft.Id.String())
+			w := httptest.NewRecorder()
+
+			SetStatusHandler(w, req, articles)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				stored, _ := articles.GetArticleById(draft.Id)
+				assert.Equal(t, article.StatusDraft, stored.Status)
+				return
+			}
+
+			var resp article.Article
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantArticle, resp.Status)
+		})
+	}
+}
+
+func TestSetVisibilityHandler(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	articles := article.NewInMemoryArticle()
+	draft, _ := articles.CreateArticle(article.Author{Id: author.Id}, "Черновик", article.FormatMarkdown, "Текст", nil)
+
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantErrorText string
+	}{
+		{name: "unlisted", body: `{"visibility":"unlisted"}`, wantStatus: http.StatusOK},
+		{name: "unknown", body: `{"visibility":"friends"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be one of public, unlisted, followers, private"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+draft.Id.String()+"/visibility", tt.body, author)
+			req.SetPathValue("id", draft.Id.String())
+			w := httptest.NewRecorder()
+
+			SetVisibilityHandler(w, req, articles)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+			stored, _ := articles.GetArticleById(draft.Id)
+			assert.Equal(t, article.VisibilityUnlisted, stored.Visibility)
+		})
+	}
+}
+
+func TestDeleteArticleHandler(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	stranger := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+
+	tests := []struct {
+		name          string
+		principal     *user.User
+		id            string
+		wantStatus    int
+		wantErrorText string
+	}{
+		{name: "own article", principal: author, wantStatus: http.StatusOK},
+		{name: "someone else's article", principal: stranger, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "invalid id", principal: author, id: "first", wantStatus: http.StatusBadRequest, wantErrorText: "invalid article id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := article.NewInMemoryArticle()
+			draft, _ := articles.CreateArticle(article.Author{Id: author.Id}, "Черновик", article.FormatMarkdown, "Текст", nil)
+			id := tt.id
+			if id == "" {
+				id = draft.Id.String()
+			}
+			req := newAuthoringRequest(http.MethodDelete, "/api/v1/articles/"+id, "", tt.principal)
+			req.SetPathValue("id", id)
+			w := httptest.NewRecorder()
+
+			DeleteArticleHandler(w, req, articles)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			_, err := articles.GetArticleById(draft.Id)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				assert.NoError(t, err, "the article is kept")
+				return
+			}
+			assert.ErrorIs(t, err, article.ErrArticleNotFound)
+		})
+	}
+}
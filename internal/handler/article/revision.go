@@ -0,0 +1,201 @@
+package article
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/worddiff"
+	"github.com/google/uuid"
+)
+
+type UpdateInput struct {
+	Title string `json:"title"`
+	// Format defaults to the current format of the article.
+	Format  article.Format `json:"format"`
+	Content string         `json:"content"`
+}
+
+type RevisionDiff struct {
+	From    int               `json:"from"`
+	To      int               `json:"to"`
+	Title   []worddiff.Change `json:"title"`
+	Content []worddiff.Change `json:"content"`
+}
+
+// UpdateArticleHandler saves a new version of an own article and records it
+// as a revision.
+func UpdateArticleHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	found, err := ownArticle(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input := new(UpdateInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if err := validateText(input.Title, input.Content); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if input.Format == "" {
+		input.Format = found.Format
+	}
+	if err := validateFormat(input.Format); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	saveRevision(w, r, articles, revisions, found.Id, input.Title, input.Format, input.Content, nil)
+}
+
+// RevisionsHandler lists the revisions of an article, oldest first.
+func RevisionsHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	articleID, err := historyArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	history, err := revisions.GetRevisions(articleID)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, history); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// RevisionDiffHandler compares the plain text of two revisions word by word,
+// so block documents diff like markdown; from and to come from the query
+// string.
+func RevisionDiffHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	articleID, err := historyArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil || from < 1 {
+		json.WriteAppError(w, apperror.Validation("from", "must be a revision number"))
+		return
+	}
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil || to < 1 {
+		json.WriteAppError(w, apperror.Validation("to", "must be a revision number"))
+		return
+	}
+
+	older, err := revisions.GetRevision(articleID, from)
+	if err != nil {
+		json.WriteAppError(w, revisionError(err))
+		return
+	}
+	newer, err := revisions.GetRevision(articleID, to)
+	if err != nil {
+		json.WriteAppError(w, revisionError(err))
+		return
+	}
+
+	diff := RevisionDiff{
+		From:    from,
+		To:      to,
+		Title:   worddiff.Diff(older.Title, newer.Title),
+		Content: worddiff.Diff(older.PlainText, newer.PlainText),
+	}
+	if err := json.Write(w, http.StatusOK, diff); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// RestoreRevisionHandler brings back the title and content of an earlier
+// revision, in the format it was written in. The restore is itself a new revision, so it can be undone.
+func RestoreRevisionHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository) {
+	articleID, err := ownArticleID(r, articles)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid revision number")
+		return
+	}
+	restored, err := revisions.GetRevision(articleID, number)
+	if err != nil {
+		json.WriteAppError(w, revisionError(err))
+		return
+	}
+
+	saveRevision(w, r, articles, revisions, articleID, restored.Title, restored.Format, restored.Content, &restored.Number)
+}
+
+func saveRevision(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	revisions revision.RevisionRepository, articleID uuid.UUID, title string, format article.Format, content string,
+	restoredFrom *int) {
+	editorID := auth.FromContext(r.Context()).User.Id
+	updated, err := articles.UpdateArticle(articleID, title, format, content, func(saved *article.Article) error {
+		_, err := revisions.AddRevision(revision.Revision{
+			ArticleId:    articleID,
+			EditorId:     editorID,
+			Title:        saved.Title,
+			Format:       saved.Format,
+			Content:      saved.Content,
+			PlainText:    saved.PlainText,
+			RestoredFrom: restoredFrom,
+		})
+		return err
+	})
+	if err != nil {
+		json.WriteAppError(w, articleError(err))
+		return
+	}
+
+	if err := json.Write(w, http.StatusOK, updated); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// historyArticleID lets authors read the history of their articles and
+// moderators read any history, including that of deleted articles.
+func historyArticleID(r *http.Request, articles article.ArticleRepository) (uuid.UUID, error) {
+	articleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, apperror.New(apperror.CodeBadRequest, "invalid article id")
+	}
+	if auth.FromContext(r.Context()).Can(rbac.ArticleHideAny) {
+		return articleID, nil
+	}
+	found, err := articles.GetArticleById(articleID)
+	if err != nil {
+		return uuid.Nil, articleError(err)
+	}
+	if found.AuthorId != auth.FromContext(r.Context()).User.Id {
+		return uuid.Nil, articleError(article.ErrArticleNotFound)
+	}
+	return articleID, nil
+}
+
+func revisionError(err error) error {
+	if errors.Is(err, revision.ErrRevisionNotFound) {
+		return apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	}
+	return err
+}
@@ -0,0 +1,219 @@
+package article
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/worddiff"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevisionHandlers(t *testing.T) {
+	author := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	stranger := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleUser}}
+	moderator := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleModerator}}
+
+	// setup saves "Первый текст" as revision 1 and "Второй текст" as revision 2
+	setup := func(t *testing.T) (*article.InMemoryArticle, *revision.InMemoryRevision, uuid.UUID) {
+		articles := article.NewInMemoryArticle()
+		revisions := revision.NewInMemoryRevision()
+		w := httptest.NewRecorder()
+		CreateArticleHandler(w, newAuthoringRequest(http.MethodPost, "/api/v1/articles", `{"title":"Заголовок","content":"Первый текст"}`, author), articles, revisions)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var created article.Article
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+		req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+created.Id.String(), `{"title":"Заголовок","content":"Второй текст"}`, author)
+		req.SetPathValue("id", created.Id.String())
+		w = httptest.NewRecorder()
+		UpdateArticleHandler(w, req, articles, revisions)
+		require.Equal(t, http.StatusOK, w.Code)
+		return articles, revisions, created.Id
+	}
+
+	t.Run("update records a revision", func(t *testing.T) {
+		articles, revisions, id := setup(t)
+		stored, _ := articles.GetArticleById(id)
+		assert.Equal(t, "Второй текст", stored.Content)
+		history, _ := revisions.GetRevisions(id)
+		require.Len(t, history, 2)
+		assert.Equal(t, "Второй текст", history[1].Content)
+		assert.Equal(t, author.Id, history[1].EditorId)
+	})
+
+	t.Run("update keeps the format by default", func(t *testing.T) {
+		articles, revisions, id := setup(t)
+		_, err := articles.UpdateArticle(id, "Заголовок", article.FormatBlocks, `{"version":1,"blocks":[{"type":"paragraph","data":{"text":"Блоки"}}]}`, nil)
+		require.NoError(t, err)
+		req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+id.String(), `{"title":"Заголовок","content":"Markdown"}`, author)
+		req.SetPathValue("id", id.String())
+		w := httptest.NewRecorder()
+		UpdateArticleHandler(w, req, articles, revisions)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		req = newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+id.String(), `{"title":"Заголовок","format":"markdown","content":"Markdown"}`, author)
+		req.SetPathValue("id", id.String())
+		w = httptest.NewRecorder()
+		UpdateArticleHandler(w, req, articles, revisions)
+		assert.Equal(t, http.StatusOK, w.Code)
+		history, _ := revisions.GetRevisions(id)
+		require.Len(t, history, 3)
+		assert.Equal(t, article.FormatMarkdown, history[2].Format)
+	})
+
+	t.Run("update of someone else's article", func(t *testing.T) {
+		articles, revisions, id := setup(t)
+		req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+id.String(), `{"title":"Чужой","content":"Текст"}`, stranger)
+		req.SetPathValue("id", id.String())
+		w := httptest.NewRecorder()
+		UpdateArticleHandler(w, req, articles, revisions)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	listTests := []struct {
+		name       string
+		principal  *user.User
+		deleted    bool
+		wantStatus int
+		wantCount  int
+	}{
+		{name: "author", principal: author, wantStatus: http.StatusOK, wantCount: 2},
+		{name: "stranger", principal: stranger, wantStatus: http.StatusNotFound},
+		{name: "moderator", principal: moderator, wantStatus: http.StatusOK, wantCount: 2},
+		{name: "author of deleted article", principal: author, deleted: true, wantStatus: http.StatusNotFound},
+		{name: "moderator of deleted article", principal: moderator, deleted: true, wantStatus: http.StatusOK, wantCount: 2},
+	}
+	for _, tt := range listTests {
+		t.Run("list as "+tt.name, func(t *testing.T) {
+			articles, revisions, id := setup(t)
+			if tt.deleted {
+				_, _ = articles.DeleteArticle(id)
+			}
+			req := newAuthoringRequest(http.MethodGet, "/api/v1/articles/"+id.String()+"/revisions", "", tt.principal)
+			req.SetPathValue("id", id.String())
+			w := httptest.NewRecorder()
+
+			RevisionsHandler(w, req, articles, revisions)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var history []revision.Revision
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+				assert.Len(t, history, tt.wantCount)
+			}
+		})
+	}
+
+	diffTests := []struct {
+		name          string
+		query         string
+		wantStatus    int
+		wantContent   []worddiff.Change
+		wantErrorText string
+	}{
+		{
+			name:        "diff",
+			query:       "from=1&to=2",
+			wantStatus:  http.StatusOK,
+			wantContent: []worddiff.Change{{Op: worddiff.OpDelete, Text: "Первый"}, {Op: worddiff.OpInsert, Text: "Второй"}, {Op: worddiff.OpEqual, Text: " текст"}},
+		},
+		{name: "missing from", query: "to=2", wantStatus: http.StatusBadRequest, wantErrorText: "must be a revision number"},
+		{name: "unknown revision", query: "from=1&to=5", wantStatus: http.StatusNotFound, wantErrorText: "revision not found"},
+	}
+	for _, tt := range diffTests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, revisions, id := setup(t)
+			req := newAuthoringRequest(http.MethodGet, "/api/v1/articles/"+id.String()+"/revisions/diff?"+tt.query, "", author)
+			req.SetPathValue("id", id.String())
+			w := httptest.NewRecorder()
+
+			RevisionDiffHandler(w, req, articles, revisions)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+			var resp RevisionDiff
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, []worddiff.Change{{Op: worddiff.OpEqual, Text: "Заголовок"}}, resp.Title)
+			assert.Equal(t, tt.wantContent, resp.Content)
+		})
+	}
+
+	t.Run("diff of block documents compares their text", func(t *testing.T) {
+		articles := article.NewInMemoryArticle()
+		revisions := revision.NewInMemoryRevision()
+		w := httptest.NewRecorder()
+		CreateArticleHandler(w, newAuthoringRequest(http.MethodPost, "/api/v1/articles",
+			`{"title":"Заголовок","format":"blocks","content":"{\"version\":1,\"blocks\":[{\"type\":\"paragraph\",\"data\":{\"text\":\"Первый текст\"}}]}"}`, author), articles, revisions)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var created article.Article
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		req := newAuthoringRequest(http.MethodPut, "/api/v1/articles/"+created.Id.String(),
+			`{"title":"Заголовок","content":"{\"version\":1,\"blocks\":[{\"type\":\"paragraph\",\"data\":{\"text\":\"Второй текст\"}}]}"}`, author)
+		req.SetPathValue("id", created.Id.String())
+		w = httptest.NewRecorder()
+		UpdateArticleHandler(w, req, articles, revisions)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req = newAuthoringRequest(http.MethodGet, "/api/v1/articles/"+created.Id.String()+"/revisions/diff?from=1&to=2", "", author)
+		req.SetPathValue("id", created.Id.String())
+		w = httptest.NewRecorder()
+		RevisionDiffHandler(w, req, articles, revisions)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp RevisionDiff
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []worddiff.Change{{Op: worddiff.OpDelete, Text: "Первый"}, {Op: worddiff.OpInsert, Text: "Второй"}, {Op: worddiff.OpEqual, Text: " текст"}}, resp.Content)
+	})
+
+	restoreTests := []struct {
+		name          string
+		principal     *user.User
+		number        string
+		wantStatus    int
+		wantErrorText string
+	}{
+		{name: "restore", principal: author, number: "1", wantStatus: http.StatusOK},
+		{name: "restore unknown revision", principal: author, number: "7", wantStatus: http.StatusNotFound, wantErrorText: "revision not found"},
+		{name: "restore invalid number", principal: author, number: "first", wantStatus: http.StatusBadRequest, wantErrorText: "invalid revision number"},
+		{name: "restore someone else's article", principal: stranger, number: "1", wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+	}
+	for _, tt := range restoreTests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, revisions, id := setup(t)
+			req := newAuthoringRequest(http.MethodPost, "/api/v1/articles/"+id.String()+"/revisions/"+tt.number+"/restore", "", tt.principal)
+			req.SetPathValue("id", id.String())
+			req.SetPathValue("number", tt.number)
+			w := httptest.NewRecorder()
+
+			RestoreRevisionHandler(w, req, articles, revisions)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+			stored, _ := articles.GetArticleById(id)
+			assert.Equal(t, "Первый текст", stored.Content)
+			history, _ := revisions.GetRevisions(id)
+			require.Len(t, history, 3)
+			if assert.NotNil(t, history[2].RestoredFrom) {
+				assert.Equal(t, 1, *history[2].RestoredFrom)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package csrf
+
+import (
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+type TokenResponse struct {
+	Token string `json:"csrf_token"`
+}
+
+func CSRFHandler(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository, protector *middleware.CSRF) {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		writeToken(w, protector, principal.SessionID)
+		return
+	}
+
+	// login and registration need a token too, so anonymous visitors get a session first
+	session, err := sessions.CreateSession()
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	cookies.SetCookie(w, session.SessionId)
+
+	writeToken(w, protector, session.SessionId)
+}
+
+func writeToken(w http.ResponseWriter, protector *middleware.CSRF, sessionID uuid.UUID) {
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.Write(w, http.StatusOK, TokenResponse{Token: protector.Token(sessionID.String())}); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
@@ -0,0 +1,92 @@
+package csrf
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFHandler(t *testing.T) {
+	type test struct {
+		name        string
+		method      string
+		setup       func(*session.InMemorySession, *http.Request) string
+		wantStatus  int
+		checkCookie bool
+	}
+
+	tests := []test{
+		{
+			name:        "no cookie creates session",
+			method:      http.MethodGet,
+			setup:       func(_ *session.InMemorySession, _ *http.Request) string { return "" },
+			wantStatus:  http.StatusOK,
+			checkCookie: true,
+		},
+		{
+			name:   "unknown session creates new one",
+			method: http.MethodGet,
+			setup: func(_ *session.InMemorySession, r *http.Request) string {
+				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(uuid.NewString())})
+				return ""
+			},
+			wantStatus:  http.StatusOK,
+			checkCookie: true,
+		},
+		{
+			name:   "existing session keeps its id",
+			method: http.MethodGet,
+			setup: func(sessions *session.InMemorySession, r *http.Request) string {
+				s, _ := sessions.CreateSession()
+				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(s.SessionId.String())})
+				return s.SessionId.String()
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/csrf", nil)
+			w := httptest.NewRecorder()
+
+			sessions := session.NewInMemorySession()
+			protector := middleware.NewCSRF([]byte("secret"), nil)
+			sessionID := tt.setup(sessions, req)
+
+			middleware.NewAuth(sessions, user.NewInMemoryUser()).OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				CSRFHandler(w, r, sessions, protector)
+			})).ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if tt.checkCookie {
+				setCookies := resp.Cookies()
+				assert.NotEmpty(t, setCookies, "cookie must be set")
+				var err error
+				sessionID, err = cookies.Decode(setCookies[0].Value)
+				assert.NoError(t, err, "cookie must be signed")
+			}
+
+			data, _ := io.ReadAll(resp.Body)
+			var tokenResp TokenResponse
+			assert.NoError(t, json.Unmarshal(data, &tokenResp))
+			assert.Equal(t, protector.Token(sessionID), tokenResp.Token)
+		})
+	}
+}
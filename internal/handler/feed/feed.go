@@ -2,49 +2,92 @@ package feed
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/visibility"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
 	article "github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
 	"github.com/google/uuid"
 )
 
-func FeedHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMemorySession, articles *article.InMemoryArticle) {
-	if r.Method != http.MethodGet {
-		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
+// Card is an article as the feed shows it: the excerpt instead of the full
+// content, which GET /articles/{id} returns.
+type Card struct {
+	Id             uuid.UUID  `json:"id"`
+	Title          string     `json:"title"`
+	Slug           string     `json:"slug"`
+	Excerpt        string     `json:"excerpt"`
+	Image          string     `json:"image"`
+	AuthorName     string     `json:"author_name"`
+	AuthorAvatar   string     `json:"author_avatar"`
+	WordCount      int        `json:"word_count"`
+	ReadingMinutes int        `json:"reading_minutes"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+}
+
+func newCard(a *article.Article) Card {
+	return Card{
+		Id:             a.Id,
+		Title:          a.Title,
+		Slug:           a.Slug,
+		Excerpt:        a.Excerpt,
+		Image:          a.Image,
+		AuthorName:     a.AuthorName,
+		AuthorAvatar:   a.AuthorAvatar,
+		WordCount:      a.WordCount,
+		ReadingMinutes: a.ReadingMinutes,
+		PublishedAt:    a.PublishedAt,
 	}
+}
 
-	cookie, err := cookies.GetCookie(r)
-	if err == nil {
-		if sessionID, parseErr := uuid.Parse(cookie.Value); parseErr == nil {
-			if _, sessErr := sessions.GetSessionById(sessionID); sessErr == nil {
-				returnFeed(w, articles)
-				return
-			}
-		}
+func FeedHandler(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository, articles article.ArticleRepository,
+	users user.UserRepository, relations relation.RelationRepository) {
+	if auth.FromContext(r.Context()) != nil {
+		returnFeed(w, r, articles, users, relations)
+		return
 	}
 
 	session, err := sessions.CreateSession()
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
 	cookies.SetCookie(w, session.SessionId)
 
-	returnFeed(w, articles)
+	returnFeed(w, r, articles, users, relations)
 }
 
-func returnFeed(w http.ResponseWriter, articles *article.InMemoryArticle) {
-	mockArticles, err := articles.GetAllArticles()
+func returnFeed(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository, users user.UserRepository,
+	relations relation.RelationRepository) {
+	filter, err := visibility.NewFilter(auth.FromContext(r.Context()), users)
 	if err != nil {
-		json.WriteError(w, http.StatusInternalServerError, err.Error())
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := filter.Personalize(relations); err != nil {
+		json.WriteAppError(w, err)
 		return
 	}
 
-	if err := json.Write(w, http.StatusOK, mockArticles); err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+	all, err := articles.GetAllArticles()
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	visible := filter.Apply(all)
+	cards := make([]Card, 0, len(visible))
+	for _, a := range visible {
+		cards = append(cards, newCard(a))
+	}
+
+	if err := json.Write(w, http.StatusOK, cards); err != nil {
+		json.WriteAppError(w, err)
 	}
 }
@@ -8,18 +8,25 @@ import (
 	"testing"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 type ArticleResponse struct {
-	Title        string `json:"title"`
-	Content      string `json:"content"`
-	Image        string `json:"image"`
-	AuthorName   string `json:"author_name"`
-	AuthorAvatar string `json:"author_avatar"`
+	Title          string  `json:"title"`
+	Slug           string  `json:"slug"`
+	Content        *string `json:"content"`
+	Excerpt        string  `json:"excerpt"`
+	Image          string  `json:"image"`
+	AuthorName     string  `json:"author_name"`
+	AuthorAvatar   string  `json:"author_avatar"`
+	WordCount      int     `json:"word_count"`
+	ReadingMinutes int     `json:"reading_minutes"`
 }
 
 type UserResponse struct {
@@ -44,14 +51,6 @@ func TestFeedHandlerStatus(t *testing.T) {
 	}
 
 	tests := []test{
-		{
-			name:          "invalid method",
-			method:        http.MethodPost,
-			setupSession:  func() (*session.InMemorySession, uuid.UUID) { return session.NewInMemorySession(), uuid.New() },
-			setupArticles: article.NewInMemoryArticle,
-			setCookie:     false,
-			wantStatus:    http.StatusMethodNotAllowed,
-		},
 		{
 			name:          "no cookie",
 			method:        http.MethodGet,
@@ -98,7 +97,7 @@ func TestFeedHandlerStatus(t *testing.T) {
 			if tt.setCookie {
 				cookieValue := tt.cookieValue
 				if tt.cookieValue == "" {
-					cookieValue = sessionID.String()
+					cookieValue = cookies.Encode(sessionID.String())
 				}
 				req.AddCookie(&http.Cookie{
 					Name:  cookies.SessionID,
@@ -106,7 +105,9 @@ func TestFeedHandlerStatus(t *testing.T) {
 				})
 			}
 
-			FeedHandler(w, req, sessions, articles)
+			middleware.NewAuth(sessions, user.NewInMemoryUser()).OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				FeedHandler(w, r, sessions, articles, user.NewInMemoryUser(), relation.NewInMemoryRelation())
+			})).ServeHTTP(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -132,16 +133,6 @@ func TestFeedHandlerResponse(t *testing.T) {
 	}
 
 	tests := []test{
-		{
-			name:          "invalid method",
-			method:        http.MethodPost,
-			setupSession:  func() (*session.InMemorySession, uuid.UUID) { return session.NewInMemorySession(), uuid.New() },
-			setupArticles: article.NewInMemoryArticle,
-			setCookie:     false,
-			wantStatus:    http.StatusMethodNotAllowed,
-			wantError:     true,
-			wantErrorText: "method not allowed",
-		},
 		{
 			name:   "no cookie creates session",
 			method: http.MethodGet,
@@ -205,7 +196,7 @@ func TestFeedHandlerResponse(t *testing.T) {
 			if tt.setCookie {
 				cookieValue := tt.cookieValue
 				if tt.cookieValue == "" {
-					cookieValue = sessionID.String()
+					cookieValue = cookies.Encode(sessionID.String())
 				}
 				req.AddCookie(&http.Cookie{
 					Name:  cookies.SessionID,
@@ -213,7 +204,9 @@ func TestFeedHandlerResponse(t *testing.T) {
 				})
 			}
 
-			FeedHandler(w, req, sessions, articles)
+			middleware.NewAuth(sessions, user.NewInMemoryUser()).OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				FeedHandler(w, r, sessions, articles, user.NewInMemoryUser(), relation.NewInMemoryRelation())
+			})).ServeHTTP(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -231,16 +224,22 @@ func TestFeedHandlerResponse(t *testing.T) {
 				assert.NoError(t, json.Unmarshal(data, &articlesResp))
 				assert.NotEmpty(t, articlesResp, "articles should be returned")
 				assert.Equal(t, "ИИ в 2025: Как нейросети меняют бизнес-процессы", articlesResp[0].Title, "article title mismatch")
-				assert.Equal(t, "Искусственный интеллект в 2025 году стал неотъемлемой частью бизнеса...", articlesResp[0].Content, "article content mismatch")
+				assert.Equal(t, "ii-v-2025-kak-neyroseti-menyayut-biznes-protsessy", articlesResp[0].Slug, "article slug mismatch")
+				assert.Equal(t, "Искусственный интеллект в 2025 году стал неотъемлемой частью бизнеса...", articlesResp[0].Excerpt, "article excerpt mismatch")
+				assert.Nil(t, articlesResp[0].Content, "the feed carries excerpts, not content")
+				assert.Equal(t, 9, articlesResp[0].WordCount, "word count mismatch")
+				assert.Equal(t, 1, articlesResp[0].ReadingMinutes, "reading time mismatch")
 				assert.Equal(t, "Алексей Владимиров", articlesResp[0].AuthorName, "author name mismatch")
 				assert.Equal(t, "https://st4.depositphotos.com/36740986/38337/i/450/depositphotos_383375990-stock-photo-collection-hundred-dollar-banknotes-female.jpg", articlesResp[0].Image, "image mismatch")
 			}
 
 			if tt.checkCookie {
-				cookies := resp.Cookies()
-				assert.NotEmpty(t, cookies, "cookie must be set")
-				assert.Equal(t, "session_id", cookies[0].Name, "cookie name mismatch")
-				_, err := uuid.Parse(cookies[0].Value)
+				setCookies := resp.Cookies()
+				assert.NotEmpty(t, setCookies, "cookie must be set")
+				assert.Equal(t, "session_id", setCookies[0].Name, "cookie name mismatch")
+				value, err := cookies.Decode(setCookies[0].Value)
+				assert.NoError(t, err, "cookie must be signed")
+				_, err = uuid.Parse(value)
 				assert.NoError(t, err, "cookie value must be valid UUID")
 			}
 		})
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/buildinfo"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checks is the readiness registry: every dependency (repository backend,
+// mailer, storage...) registers a CheckFunc that must pass within the timeout.
+type Checks struct {
+	checks       []check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+	mu           sync.RWMutex
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func NewChecks(timeout time.Duration) *Checks {
+	return &Checks{timeout: timeout}
+}
+
+func (c *Checks) Register(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing here
+// while in-flight requests drain.
+func (c *Checks) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checks) Run(ctx context.Context) (map[string]string, bool) {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make(map[string]string, len(checks))
+	ready := true
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, ch := range checks {
+		wg.Add(1)
+		go func(ch check) {
+			defer wg.Done()
+			err := runCheck(ctx, c.timeout, ch.fn)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[ch.name] = err.Error()
+				ready = false
+				return
+			}
+			results[ch.name] = "ok"
+		}(ch)
+	}
+	wg.Wait()
+
+	if c.shuttingDown.Load() {
+		results["shutdown"] = "server is shutting down"
+		ready = false
+	}
+	return results, ready
+}
+
+func runCheck(ctx context.Context, timeout time.Duration, fn CheckFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("timeout")
+	}
+}
+
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	json.Write(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func ReadyzHandler(w http.ResponseWriter, r *http.Request, checks *Checks) {
+	results, ready := checks.Run(r.Context())
+	if !ready {
+		json.Write(w, http.StatusServiceUnavailable, ReadinessResponse{Status: "unavailable", Checks: results})
+		return
+	}
+	json.Write(w, http.StatusOK, ReadinessResponse{Status: "ok", Checks: results})
+}
+
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	json.Write(w, http.StatusOK, buildinfo.Get())
+}
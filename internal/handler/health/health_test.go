@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/buildinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyzHandler(t *testing.T) {
+	type test struct {
+		name       string
+		setup      func(*Checks)
+		wantStatus int
+		wantChecks map[string]string
+	}
+
+	tests := []test{
+		{
+			name:       "no checks",
+			setup:      func(_ *Checks) {},
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{},
+		},
+		{
+			name: "all checks pass",
+			setup: func(c *Checks) {
+				c.Register("sessions", func(ctx context.Context) error { return nil })
+				c.Register("users", func(ctx context.Context) error { return nil })
+			},
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"sessions": "ok", "users": "ok"},
+		},
+		{
+			name: "failing check",
+			setup: func(c *Checks) {
+				c.Register("sessions", func(ctx context.Context) error { return nil })
+				c.Register("mailer", func(ctx context.Context) error { return errors.New("connection refused") })
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"sessions": "ok", "mailer": "connection refused"},
+		},
+		{
+			name: "slow check times out",
+			setup: func(c *Checks) {
+				c.Register("storage", func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				})
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"storage": "timeout"},
+		},
+		{
+			name: "shutting down",
+			setup: func(c *Checks) {
+				c.Register("sessions", func(ctx context.Context) error { return nil })
+				c.SetShuttingDown()
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"sessions": "ok", "shutdown": "server is shutting down"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := NewChecks(20 * time.Millisecond)
+			tt.setup(checks)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+
+			ReadyzHandler(w, req, checks)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			data, _ := io.ReadAll(resp.Body)
+			var body ReadinessResponse
+			assert.NoError(t, json.Unmarshal(data, &body))
+			assert.Equal(t, tt.wantChecks, body.Checks)
+		})
+	}
+}
+
+func TestHealthzHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	HealthzHandler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"status":"ok"}`, string(data))
+}
+
+func TestVersionHandler(t *testing.T) {
+	prevCommit, prevTime := buildinfo.Commit, buildinfo.BuildTime
+	buildinfo.Commit, buildinfo.BuildTime = "abc123", "2025-10-01T12:00:00Z"
+	t.Cleanup(func() { buildinfo.Commit, buildinfo.BuildTime = prevCommit, prevTime })
+
+	w := httptest.NewRecorder()
+	VersionHandler(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	var info buildinfo.Info
+	assert.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "abc123", info.Commit)
+	assert.Equal(t, "2025-10-01T12:00:00Z", info.BuildTime)
+	assert.NotEmpty(t, info.GoVersion)
+}
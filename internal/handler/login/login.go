@@ -1,37 +1,44 @@
 package login
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	userrepo "github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
 )
 
+// errInvalidCredentials answers both an unknown email and a wrong password,
+// so the response doesn't reveal which emails have accounts.
+var errInvalidCredentials = apperror.New(apperror.CodeUnauthorized, "invalid email or password")
+
 type UserLoginInput struct {
-	Email    string
-	Password string
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
-func LoginHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMemorySession,
-	users *user.InMemoryUser) {
-	if r.Method != http.MethodPost {
-		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
+func LoginHandler(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository,
+	users userrepo.UserRepository, protector *middleware.CSRF) {
 	newUserData := new(UserLoginInput)
-	err := json.Read(r, newUserData)
+	err := json.Read(w, r, newUserData)
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		metrics.LoginFailures.WithLabelValues("bad_request").Inc()
+		json.WriteAppError(w, err)
 		return
 	}
 
 	if newUserData.Email == "" || newUserData.Password == "" {
+		metrics.LoginFailures.WithLabelValues("bad_request").Inc()
 		json.WriteError(w, http.StatusBadRequest, "Email or Password is required")
 		return
 	}
@@ -40,36 +47,66 @@ func LoginHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMe
 	password := newUserData.Password
 
 	user, err := users.GetUserByEmail(email)
+	if errors.Is(err, userrepo.ErrUserNotFound) {
+		metrics.LoginFailures.WithLabelValues("user_not_found").Inc()
+		json.WriteAppError(w, errInvalidCredentials)
+		return
+	}
 	if err != nil {
-		json.WriteError(w, http.StatusNotFound, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
 
-	log.Println("FOUND:", user.Email, user.Password)
-
 	if user.Password != password {
-		json.WriteError(w, http.StatusUnauthorized, "invalid password")
+		logger.FromContext(r.Context()).Warn("login failed", "user_id", user.Id.String())
+		metrics.LoginFailures.WithLabelValues("invalid_password").Inc()
+		json.WriteAppError(w, errInvalidCredentials)
 		return
 	}
-
-	session, err := sessions.CreateSession()
-	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+	if ban := user.ActiveRestriction(userrepo.RestrictionBan); ban != nil {
+		logger.FromContext(r.Context()).Warn("login refused", "user_id", user.Id.String(), "reason", "banned")
+		metrics.LoginFailures.WithLabelValues("banned").Inc()
+		json.WriteError(w, http.StatusForbidden, fmt.Sprintf("account is banned %s: %s", ban.Until(), ban.Reason))
 		return
 	}
+	logger.SetUserID(r.Context(), user.Id.String())
 
-	cookies.SetCookie(w, session.SessionId)
-
-	_, err = sessions.SetSessionUserId(session.SessionId, user.Id)
-	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+	if err := StartSession(w, r, sessions, protector, user.Id); err != nil {
+		json.WriteAppError(w, err)
 		return
 	}
 
 	err = json.Write(w, http.StatusOK, user)
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
+}
+
+// StartSession signs the user in on a fresh session, so a session id set
+// before login can't be used to ride the account. The anonymous session the
+// request came with is deleted, and the CSRF token of the new one is sent in
+// the X-CSRF-Token header, since the old token no longer matches the cookie.
+func StartSession(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository,
+	protector *middleware.CSRF, userID uuid.UUID) error {
+	fresh, err := sessions.CreateSession()
+	if err != nil {
+		return err
+	}
+	if _, err := sessions.SetSessionUserId(fresh.SessionId, userID); err != nil {
+		return err
+	}
+
+	if cookie, err := cookies.GetCookie(r); err == nil {
+		if previous, err := uuid.Parse(cookie.Value); err == nil {
+			if _, err := sessions.DeleteSessionById(previous); err != nil && !errors.Is(err, session.ErrSessionNotFound) {
+				return err
+			}
+		}
+	}
 
+	cookies.SetCookie(w, fresh.SessionId)
+	w.Header().Set(middleware.CSRFHeader, protector.Token(fresh.SessionId.String()))
+	w.Header().Set("Cache-Control", "no-store")
+	return nil
 }
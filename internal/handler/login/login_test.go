@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 	"github.com/google/uuid"
@@ -54,7 +56,7 @@ func TestLoginStatus(t *testing.T) {
 			name:       "user not found",
 			body:       `{"email":"ghost@mail.com","password":"123"}`,
 			setupUsers: user.NewInMemoryUser,
-			wantStatus: http.StatusNotFound,
+			wantStatus: http.StatusUnauthorized,
 		},
 		{
 			name: "invalid password",
@@ -66,6 +68,17 @@ func TestLoginStatus(t *testing.T) {
 			},
 			wantStatus: http.StatusUnauthorized,
 		},
+		{
+			name: "banned user",
+			body: `{"email":"user@mail.com","password":"123"}`,
+			setupUsers: func() *user.InMemoryUser {
+				users := user.NewInMemoryUser()
+				u, _ := users.CreateUser("user@mail.com", "123", "Test User")
+				_, _ = users.Restrict(u.Id, user.Restriction{Kind: user.RestrictionBan, Reason: "spam"})
+				return users
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "success login",
 			body: `{"email":"user@mail.com","password":"123"}`,
@@ -81,12 +94,13 @@ func TestLoginStatus(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			sessions := session.NewInMemorySession()
 			users := tt.setupUsers()
 
-			LoginHandler(w, req, sessions, users)
+			LoginHandler(w, req, sessions, users, middleware.NewCSRF([]byte("secret"), nil))
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -121,9 +135,9 @@ func TestLoginResponse(t *testing.T) {
 			name:          "user not found",
 			body:          `{"email":"ghost@mail.com","password":"123"}`,
 			setupUsers:    user.NewInMemoryUser,
-			wantStatus:    http.StatusNotFound,
+			wantStatus:    http.StatusUnauthorized,
 			wantError:     true,
-			wantErrorText: "user not found",
+			wantErrorText: "invalid email or password",
 		},
 		{
 			name: "invalid password",
@@ -135,7 +149,7 @@ func TestLoginResponse(t *testing.T) {
 			},
 			wantStatus:    http.StatusUnauthorized,
 			wantError:     true,
-			wantErrorText: "invalid password",
+			wantErrorText: "invalid email or password",
 		},
 		{
 			name: "success login",
@@ -154,12 +168,13 @@ func TestLoginResponse(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			sessions := session.NewInMemorySession()
 			users := tt.setupUsers()
 
-			LoginHandler(w, req, sessions, users)
+			LoginHandler(w, req, sessions, users, middleware.NewCSRF([]byte("secret"), nil))
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -178,12 +193,66 @@ func TestLoginResponse(t *testing.T) {
 				assert.Equal(t, tt.wantEmail, userResp.Email, "email mismatch")
 
 				if tt.checkCookie {
-					cookies := resp.Cookies()
-					assert.NotEmpty(t, cookies, "cookie must be set on login")
-					_, err := uuid.Parse(cookies[0].Value)
+					setCookies := resp.Cookies()
+					assert.NotEmpty(t, setCookies, "cookie must be set on login")
+					value, err := cookies.Decode(setCookies[0].Value)
+					assert.NoError(t, err, "cookie must be signed")
+					_, err = uuid.Parse(value)
 					assert.NoError(t, err, "cookie value must be valid UUID")
 				}
 			}
 		})
 	}
 }
+
+func TestLoginDoesNotRevealAccounts(t *testing.T) {
+	users := user.NewInMemoryUser()
+	_, _ = users.CreateUser("user@mail.com", "123", "Test User")
+	sessions := session.NewInMemorySession()
+
+	login := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		LoginHandler(w, req, sessions, users, middleware.NewCSRF([]byte("secret"), nil))
+		return w
+	}
+
+	unknown := login(`{"email":"ghost@mail.com","password":"123"}`)
+	wrong := login(`{"email":"user@mail.com","password":"wrong"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, unknown.Code)
+	assert.Equal(t, wrong.Code, unknown.Code)
+	assert.JSONEq(t, `{"error":"invalid email or password","code":"unauthorized"}`, unknown.Body.String())
+	assert.Equal(t, wrong.Body.String(), unknown.Body.String())
+}
+
+func TestLoginRotatesSession(t *testing.T) {
+	users := user.NewInMemoryUser()
+	registered, _ := users.CreateUser("user@mail.com", "123", "Test User")
+	sessions := session.NewInMemorySession()
+	protector := middleware.NewCSRF([]byte("secret"), nil)
+	anonymous, _ := sessions.CreateSession()
+
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"user@mail.com","password":"123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: cookies.Name(), Value: cookies.Encode(anonymous.SessionId.String())})
+	w := httptest.NewRecorder()
+
+	LoginHandler(w, req, sessions, users, protector)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, err := sessions.GetSessionById(anonymous.SessionId)
+	assert.ErrorIs(t, err, session.ErrSessionNotFound, "the anonymous session is deleted")
+
+	setCookies := w.Result().Cookies()
+	if assert.NotEmpty(t, setCookies) {
+		value, _ := cookies.Decode(setCookies[0].Value)
+		assert.NotEqual(t, anonymous.SessionId.String(), value)
+		fresh, err := sessions.GetSessionById(uuid.MustParse(value))
+		if assert.NoError(t, err) {
+			assert.Equal(t, registered.Id, fresh.UserId)
+		}
+		assert.Equal(t, protector.Token(value), w.Header().Get(middleware.CSRFHeader), "the token matches the new cookie")
+	}
+}
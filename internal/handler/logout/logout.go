@@ -1,40 +1,30 @@
 package logout
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
-
-	"github.com/google/uuid"
 )
 
-func LogoutHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMemorySession) {
-	if r.Method != http.MethodPost {
-		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
-	cookie, err := cookies.GetCookie(r)
-	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+func LogoutHandler(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository) {
+	principal := auth.FromContext(r.Context())
+	if !principal.Authenticated() {
+		json.WriteAppError(w, auth.ErrUnauthorized)
 		return
 	}
 
-	err = cookies.DeleteCookie(w, r)
+	err := cookies.DeleteCookie(w, r)
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, apperror.Wrap(apperror.CodeBadRequest, err.Error(), err))
 		return
 	}
 
-	sessionId, err := uuid.Parse(cookie.Value)
-	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	flag, err := sessions.DeleteSessionById(sessionId)
+	flag, err := sessions.DeleteSessionById(principal.SessionID)
 	if flag {
 		json.Write(w, http.StatusOK, map[string]string{
 			"message": "logged out",
@@ -42,5 +32,10 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request, sessions *session.InM
 		return
 	}
 
-	json.WriteError(w, http.StatusBadRequest, err.Error())
+	// the session expired between authentication and now
+	if errors.Is(err, session.ErrSessionNotFound) {
+		json.WriteAppError(w, auth.ErrUnauthorized)
+		return
+	}
+	json.WriteAppError(w, err)
 }
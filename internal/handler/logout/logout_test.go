@@ -1,13 +1,15 @@
 package logout
 
 import (
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +17,7 @@ import (
 func TestLogoutHandler(t *testing.T) {
 	type test struct {
 		name       string
-		setup      func(*session.InMemorySession, *http.Request)
+		setup      func(*session.InMemorySession, *user.InMemoryUser, *http.Request)
 		wantStatus int
 		wantBody   string
 	}
@@ -23,30 +25,40 @@ func TestLogoutHandler(t *testing.T) {
 	tests := []test{
 		{
 			name:       "no cookie",
-			setup:      func(_ *session.InMemorySession, _ *http.Request) {},
-			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"error":"cookie not found"}`,
+			setup:      func(_ *session.InMemorySession, _ *user.InMemoryUser, _ *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":"authentication required","code":"unauthorized"}`,
 		},
 		{
 			name: "invalid cookie value (not uuid)",
-			setup: func(_ *session.InMemorySession, r *http.Request) {
+			setup: func(_ *session.InMemorySession, _ *user.InMemoryUser, r *http.Request) {
 				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: "not-a-uuid"})
 			},
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusUnauthorized,
 		},
 		{
 			name: "valid uuid but session not found",
-			setup: func(_ *session.InMemorySession, r *http.Request) {
-				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: uuid.NewString()})
+			setup: func(_ *session.InMemorySession, _ *user.InMemoryUser, r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(uuid.NewString())})
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":"authentication required","code":"unauthorized"}`,
+		},
+		{
+			name: "anonymous session",
+			setup: func(sessions *session.InMemorySession, _ *user.InMemoryUser, r *http.Request) {
+				session, _ := sessions.CreateSession()
+				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(session.SessionId.String())})
 			},
-			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"error":"session not found"}`,
+			wantStatus: http.StatusUnauthorized,
 		},
 		{
 			name: "valid session logout",
-			setup: func(sessions *session.InMemorySession, r *http.Request) {
+			setup: func(sessions *session.InMemorySession, users *user.InMemoryUser, r *http.Request) {
 				session, _ := sessions.CreateSession()
-				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: session.SessionId.String()})
+				u, _ := users.CreateUser("user@mail.com", "password1", "TestUser")
+				_, _ = sessions.SetSessionUserId(session.SessionId, u.Id)
+				r.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(session.SessionId.String())})
 			},
 			wantStatus: http.StatusOK,
 			wantBody:   `{"message":"logged out"}`,
@@ -59,9 +71,12 @@ func TestLogoutHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			sessions := session.NewInMemorySession()
-			test.setup(sessions, req)
+			users := user.NewInMemoryUser()
+			test.setup(sessions, users, req)
 
-			LogoutHandler(w, req, sessions)
+			middleware.NewAuth(sessions, users).RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				LogoutHandler(w, r, sessions)
+			})).ServeHTTP(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -3,47 +3,20 @@ package me
 import (
 	"net/http"
 
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
-
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
-	"github.com/google/uuid"
 )
 
-func MeHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMemorySession, users *user.InMemoryUser) {
-	if r.Method != http.MethodGet {
-		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
-	cookie, err := cookies.GetCookie(r)
-	if err != nil {
-		json.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	sessionID, err := uuid.Parse(cookie.Value)
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-
-	session, err := sessions.GetSessionById(sessionID)
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-
-	user, err := users.GetUserById(session.UserId)
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+func MeHandler(w http.ResponseWriter, r *http.Request) {
+	principal := auth.FromContext(r.Context())
+	if !principal.Authenticated() {
+		json.WriteAppError(w, auth.ErrUnauthorized)
 		return
 	}
 
-	err = json.Write(w, http.StatusOK, user)
+	err := json.Write(w, http.StatusOK, principal.User)
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
 }
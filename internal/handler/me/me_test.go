@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 
@@ -37,18 +38,6 @@ func TestMeHandlerStatus(t *testing.T) {
 	}
 
 	tests := []test{
-		{
-			name:   "invalid method",
-			method: http.MethodPost,
-			setupSession: func(userID uuid.UUID) (*session.InMemorySession, uuid.UUID) {
-				return session.NewInMemorySession(), uuid.New()
-			},
-			setupUsers: func() (*user.InMemoryUser, uuid.UUID) {
-				return user.NewInMemoryUser(), uuid.New()
-			},
-			setCookie:  false,
-			wantStatus: http.StatusMethodNotAllowed,
-		},
 		{
 			name:   "no cookie",
 			method: http.MethodGet,
@@ -148,7 +137,7 @@ func TestMeHandlerStatus(t *testing.T) {
 			if tt.setCookie {
 				cookieValue := tt.cookieValue
 				if tt.cookieValue == "" {
-					cookieValue = sessionID.String()
+					cookieValue = cookies.Encode(sessionID.String())
 				}
 				req.AddCookie(&http.Cookie{
 					Name:  cookies.SessionID,
@@ -156,7 +145,7 @@ func TestMeHandlerStatus(t *testing.T) {
 				})
 			}
 
-			MeHandler(w, req, sessions, users)
+			middleware.NewAuth(sessions, users).RequireAuth(http.HandlerFunc(MeHandler)).ServeHTTP(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -181,20 +170,6 @@ func TestMeHandlerResponse(t *testing.T) {
 	}
 
 	tests := []test{
-		{
-			name:   "invalid method",
-			method: http.MethodPost,
-			setupSession: func(userID uuid.UUID) (*session.InMemorySession, uuid.UUID) {
-				return session.NewInMemorySession(), uuid.New()
-			},
-			setupUsers: func() (*user.InMemoryUser, uuid.UUID) {
-				return user.NewInMemoryUser(), uuid.New()
-			},
-			setCookie:     false,
-			wantStatus:    http.StatusMethodNotAllowed,
-			wantError:     true,
-			wantErrorText: "method not allowed",
-		},
 		{
 			name:   "no cookie",
 			method: http.MethodGet,
@@ -207,7 +182,7 @@ func TestMeHandlerResponse(t *testing.T) {
 			setCookie:     false,
 			wantStatus:    http.StatusUnauthorized,
 			wantError:     true,
-			wantErrorText: "cookie not found",
+			wantErrorText: "authentication required",
 		},
 		{
 			name:   "invalid cookie",
@@ -300,7 +275,7 @@ func TestMeHandlerResponse(t *testing.T) {
 			if tt.setCookie {
 				cookieValue := tt.cookieValue
 				if tt.cookieValue == "" {
-					cookieValue = sessionID.String()
+					cookieValue = cookies.Encode(sessionID.String())
 				}
 				req.AddCookie(&http.Cookie{
 					Name:  cookies.SessionID,
@@ -317,7 +292,7 @@ func TestMeHandlerResponse(t *testing.T) {
 				assert.Equal(t, userID, session.UserId, "session userID mismatch")
 			}
 
-			MeHandler(w, req, sessions, users)
+			middleware.NewAuth(sessions, users).RequireAuth(http.HandlerFunc(MeHandler)).ServeHTTP(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -0,0 +1,384 @@
+package moderation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/visibility"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+const (
+	maxDetailsLength = 1000
+	maxNoteLength    = 1000
+
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+type ReportInput struct {
+	Reason  report.Reason `json:"reason"`
+	Details string        `json:"details"`
+}
+
+type ResolveInput struct {
+	Action report.Action `json:"action"`
+	Note   string        `json:"note"`
+	// ExpiresAt makes a ban temporary; it is unset for a permanent one.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// actionPermissions is what a moderator needs on top of report.review to
+// take each action.
+var actionPermissions = map[report.Action]rbac.Permission{
+	report.ActionDismiss: rbac.ReportReview,
+	report.ActionHide:    rbac.ArticleHideAny,
+	report.ActionDelete:  rbac.ArticleDeleteAny,
+	report.ActionWarn:    rbac.UserWarn,
+	report.ActionBan:     rbac.UserBan,
+}
+
+var auditActions = map[report.Action]string{
+	report.ActionDismiss: audit.ActionReportDismiss,
+	report.ActionHide:    audit.ActionArticleHide,
+	report.ActionDelete:  audit.ActionArticleDelete,
+	report.ActionWarn:    audit.ActionUserWarn,
+	report.ActionBan:     audit.ActionUserBan,
+}
+
+// ReportArticleHandler accepts reports of articles the reporter can open,
+// followers-only ones of followed authors included.
+func ReportArticleHandler(w http.ResponseWriter, r *http.Request, articles article.ArticleRepository,
+	users user.UserRepository, relations relation.RelationRepository, reports report.ReportRepository) {
+	articleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid article id")
+		return
+	}
+	input, err := readReportInput(w, r)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	found, err := articles.GetArticleById(articleID)
+	if err != nil {
+		json.WriteAppError(w, lookupError(err))
+		return
+	}
+	filter, err := visibility.NewFilter(auth.FromContext(r.Context()), users)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := filter.Personalize(relations); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if !filter.CanSee(found) {
+		json.WriteError(w, http.StatusNotFound, "article not found")
+		return
+	}
+	reporter := auth.FromContext(r.Context()).User
+	if found.AuthorId == reporter.Id {
+		json.WriteError(w, http.StatusBadRequest, "you cannot report your own article")
+		return
+	}
+
+	createReport(w, r, reports, report.TargetArticle, articleID, input)
+}
+
+func ReportUserHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository, reports report.ReportRepository) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	input, err := readReportInput(w, r)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	if _, err := users.GetUserById(userID); err != nil {
+		json.WriteAppError(w, lookupError(err))
+		return
+	}
+	if auth.FromContext(r.Context()).User.Id == userID {
+		json.WriteError(w, http.StatusBadRequest, "you cannot report yourself")
+		return
+	}
+
+	createReport(w, r, reports, report.TargetUser, userID, input)
+}
+
+func readReportInput(w http.ResponseWriter, r *http.Request) (*ReportInput, error) {
+	input := new(ReportInput)
+	if err := json.Read(w, r, input); err != nil {
+		return nil, err
+	}
+	if !input.Reason.Valid() {
+		return nil, apperror.Validation("reason", "must be one of spam, abuse, harassment, misinformation, illegal, other")
+	}
+	if utf8.RuneCountInString(input.Details) > maxDetailsLength {
+		return nil, apperror.Validation("details", "must be at most 1000 characters")
+	}
+	return input, nil
+}
+
+func createReport(w http.ResponseWriter, r *http.Request, reports report.ReportRepository,
+	targetType report.TargetType, targetID uuid.UUID, input *ReportInput) {
+	reporter := auth.FromContext(r.Context()).User
+	created, err := reports.CreateReport(targetType, targetID, reporter.Id, input.Reason, input.Details)
+	if err != nil {
+		json.WriteAppError(w, reportError(err))
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("report created",
+		"report_id", created.Id.String(),
+		"target_type", string(targetType),
+		"target_id", targetID.String(),
+		"reason", string(input.Reason),
+	)
+	if err := json.Write(w, http.StatusCreated, created); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// ListReportsHandler returns the queue for one status, open by default.
+func ListReportsHandler(w http.ResponseWriter, r *http.Request, reports report.ReportRepository) {
+	status := report.StatusOpen
+	if value := r.URL.Query().Get("status"); value != "" {
+		status = report.Status(value)
+	}
+	if !status.Valid() {
+		json.WriteAppError(w, apperror.Validation("status", "must be one of open, claimed, resolved"))
+		return
+	}
+
+	list, err := reports.GetReportsByStatus(status)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := json.Write(w, http.StatusOK, list); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+func ClaimReportHandler(w http.ResponseWriter, r *http.Request, reports report.ReportRepository, auditLog audit.AuditLog) {
+	reportID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid report id")
+		return
+	}
+
+	moderator := auth.FromContext(r.Context()).User
+	claimed, err := reports.ClaimReport(reportID, moderator.Id)
+	if err != nil {
+		json.WriteAppError(w, reportError(err))
+		return
+	}
+
+	record(r, auditLog, audit.Entry{
+		ActorId:    moderator.Id,
+		Action:     audit.ActionReportClaim,
+		TargetType: string(claimed.TargetType),
+		TargetId:   claimed.TargetId,
+		ReportId:   &claimed.Id,
+	})
+	if err := json.Write(w, http.StatusOK, claimed); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// ResolveReportHandler applies the moderator's decision to the reported
+// content and closes every open report about it. The repository applies the
+// action while it closes the report, so two moderators can't both act on one
+// report and a failed action leaves the queue intact.
+func ResolveReportHandler(w http.ResponseWriter, r *http.Request, reports report.ReportRepository,
+	articles article.ArticleRepository, users user.UserRepository, sessions session.SessionRepository, auditLog audit.AuditLog) {
+	reportID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid report id")
+		return
+	}
+	input := new(ResolveInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	permission, ok := actionPermissions[input.Action]
+	if !ok {
+		json.WriteAppError(w, apperror.Validation("action", "must be one of dismiss, hide, delete, warn, ban"))
+		return
+	}
+	if utf8.RuneCountInString(input.Note) > maxNoteLength {
+		json.WriteAppError(w, apperror.Validation("note", "must be at most 1000 characters"))
+		return
+	}
+	if input.ExpiresAt != nil {
+		if input.Action != report.ActionBan {
+			json.WriteAppError(w, apperror.Validation("expires_at", "applies only to bans"))
+			return
+		}
+		if !input.ExpiresAt.After(time.Now()) {
+			json.WriteAppError(w, apperror.Validation("expires_at", "must be in the future"))
+			return
+		}
+	}
+	if err := auth.Require(r.Context(), permission); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	moderator := auth.FromContext(r.Context()).User
+	var targetType string
+	var targetID uuid.UUID
+	resolved, err := reports.ResolveReport(reportID, moderator.Id, input.Action, input.Note, func(pending *report.Report) error {
+		var err error
+		targetType, targetID, err = apply(r, input, pending, articles, users, sessions)
+		return err
+	})
+	if err != nil {
+		json.WriteAppError(w, reportError(err))
+		return
+	}
+
+	record(r, auditLog, audit.Entry{
+		ActorId:    moderator.Id,
+		Action:     auditActions[input.Action],
+		TargetType: targetType,
+		TargetId:   targetID,
+		ReportId:   &reportID,
+		Note:       input.Note,
+	})
+	if err := json.Write(w, http.StatusOK, resolved); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// apply carries out the action and returns what it was applied to: warn and
+// ban on an article report act on the article's author. A ban is permanent
+// unless the input sets its expiry.
+func apply(r *http.Request, input *ResolveInput, pending *report.Report, articles article.ArticleRepository,
+	users user.UserRepository, sessions session.SessionRepository) (string, uuid.UUID, error) {
+	action := input.Action
+	if action == report.ActionDismiss {
+		return string(pending.TargetType), pending.TargetId, nil
+	}
+
+	userID := pending.TargetId
+	if pending.TargetType == report.TargetArticle {
+		found, err := articles.GetArticleById(pending.TargetId)
+		if err != nil {
+			return "", uuid.Nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+		}
+
+		switch action {
+		case report.ActionHide:
+			if _, err := articles.HideArticle(found.Id); err != nil {
+				return "", uuid.Nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+			}
+			return string(report.TargetArticle), found.Id, nil
+		case report.ActionDelete:
+			if _, err := articles.DeleteArticle(found.Id); err != nil {
+				return "", uuid.Nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+			}
+			return string(report.TargetArticle), found.Id, nil
+		}
+		userID = found.AuthorId
+	} else if action == report.ActionHide || action == report.ActionDelete {
+		return "", uuid.Nil, apperror.Validation("action", "hide and delete apply only to article reports")
+	}
+
+	if action == report.ActionWarn {
+		if _, err := users.WarnUser(userID); err != nil {
+			return "", uuid.Nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+		}
+		return string(report.TargetUser), userID, nil
+	}
+
+	reason := input.Note
+	if reason == "" {
+		reason = string(pending.Reason)
+	}
+	if _, err := restrict(r, users, sessions, userID, user.RestrictionBan, reason, input.ExpiresAt); err != nil {
+		return "", uuid.Nil, err
+	}
+	return string(report.TargetUser), userID, nil
+}
+
+// AuditHandler returns the newest audit entries; ?limit= caps the count.
+func AuditHandler(w http.ResponseWriter, r *http.Request, auditLog audit.AuditLog) {
+	limit := defaultAuditLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAuditLimit {
+			json.WriteAppError(w, apperror.Validation("limit", "must be an integer between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := auditLog.GetEntries(limit)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := json.Write(w, http.StatusOK, entries); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+// record never fails the request: the decision has already been applied,
+// so a lost audit entry is logged rather than reported to the moderator.
+func record(r *http.Request, auditLog audit.AuditLog, entry audit.Entry) {
+	log := logger.FromContext(r.Context())
+	if _, err := auditLog.Record(entry); err != nil {
+		log.Error("audit entry not recorded", "action", entry.Action, "error", err)
+		return
+	}
+	log.Info("moderation decision",
+		"actor_id", entry.ActorId.String(),
+		"action", entry.Action,
+		"target_type", entry.TargetType,
+		"target_id", entry.TargetId.String(),
+	)
+}
+
+// lookupError answers 404 for a missing article or user and passes any
+// other failure on as is.
+func lookupError(err error) error {
+	if errors.Is(err, article.ErrArticleNotFound) || errors.Is(err, user.ErrUserNotFound) {
+		return apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	}
+	return err
+}
+
+func reportError(err error) error {
+	switch {
+	case errors.Is(err, report.ErrReportNotFound):
+		return apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	case errors.Is(err, report.ErrDuplicateReport),
+		errors.Is(err, report.ErrAlreadyClaimed),
+		errors.Is(err, report.ErrAlreadyResolved):
+		return apperror.Wrap(apperror.CodeConflict, err.Error(), err)
+	}
+	return err
+}
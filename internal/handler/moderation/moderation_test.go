@@ -0,0 +1,353 @@
+package moderation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func newRequest(method, target, body string, principal *user.User) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{SessionID: uuid.New(), User: principal}))
+}
+
+func TestReportArticleHandler(t *testing.T) {
+	users := user.NewInMemoryUser()
+	reporter, _ := users.CreateUser("reporter@mail.ru", "password1", "Reporter")
+	articles := article.NewInMemoryArticle()
+	foreign, _ := articles.CreateArticle(article.Author{Id: uuid.New()}, "Чужая", article.FormatMarkdown, "Текст", nil)
+	own, _ := articles.CreateArticle(article.Author{Id: reporter.Id}, "Своя", article.FormatMarkdown, "Текст", nil)
+	_, _ = articles.SetStatus(foreign.Id, article.StatusPublished, nil)
+	_, _ = articles.SetStatus(own.Id, article.StatusPublished, nil)
+	followed, unfollowed := uuid.New(), uuid.New()
+	ofFollowed, _ := articles.CreateArticle(article.Author{Id: followed}, "Для подписчиков", article.FormatMarkdown, "Текст", nil)
+	ofUnfollowed, _ := articles.CreateArticle(article.Author{Id: unfollowed}, "Для чужих подписчиков", article.FormatMarkdown, "Текст", nil)
+	for _, a := range []*article.Article{ofFollowed, ofUnfollowed} {
+		_, _ = articles.SetStatus(a.Id, article.StatusPublished, nil)
+		_, _ = articles.SetVisibility(a.Id, article.VisibilityFollowers)
+	}
+	relations := relation.NewInMemoryRelation()
+	_ = relations.Follow(reporter.Id, followed)
+
+	tests := []struct {
+		name          string
+		id            string
+		body          string
+		wantStatus    int
+		wantErrorText string
+	}{
+		{name: "created", id: foreign.Id.String(), body: `{"reason":"spam","details":"реклама казино"}`, wantStatus: http.StatusCreated},
+		{name: "duplicate", id: foreign.Id.String(), body: `{"reason":"abuse"}`, wantStatus: http.StatusConflict, wantErrorText: "you have already reported this"},
+		{name: "unknown reason", id: foreign.Id.String(), body: `{"reason":"boring"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be one of spam, abuse, harassment, misinformation, illegal, other"},
+		{name: "own article", id: own.Id.String(), body: `{"reason":"spam"}`, wantStatus: http.StatusBadRequest, wantErrorText: "you cannot report your own article"},
+		{name: "followers-only of a followed author", id: ofFollowed.Id.String(), body: `{"reason":"spam"}`, wantStatus: http.StatusCreated},
+		{name: "followers-only of another author", id: ofUnfollowed.Id.String(), body: `{"reason":"spam"}`, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "unknown article", id: uuid.NewString(), body: `{"reason":"spam"}`, wantStatus: http.StatusNotFound, wantErrorText: "article not found"},
+		{name: "invalid id", id: "42", body: `{"reason":"spam"}`, wantStatus: http.StatusBadRequest, wantErrorText: "invalid article id"},
+	}
+
+	reports := report.NewInMemoryReport()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(http.MethodPost, "/api/v1/articles/"+tt.id+"/report", tt.body, reporter)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			ReportArticleHandler(w, req, articles, users, relations, reports)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+
+			var created report.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+			assert.Equal(t, report.TargetArticle, created.TargetType)
+			assert.Equal(t, tt.id, created.TargetId.String())
+			assert.Equal(t, reporter.Id, created.ReporterId)
+			assert.Equal(t, report.StatusOpen, created.Status)
+		})
+	}
+}
+
+func TestResolveReportHandler(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		targetUser    bool
+		role          rbac.Role
+		claimedByPeer bool
+		body          string
+		wantStatus    int
+		wantErrorText string
+		wantAudit     string
+		check         func(t *testing.T, articles *article.InMemoryArticle, users *user.InMemoryUser, target uuid.UUID)
+	}{
+		{
+			name:       "dismiss",
+			role:       rbac.RoleModerator,
+			body:       `{"action":"dismiss","note":"not spam"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionReportDismiss,
+			check: func(t *testing.T, articles *article.InMemoryArticle, _ *user.InMemoryUser, target uuid.UUID) {
+				got, _ := articles.GetArticleById(target)
+				assert.False(t, got.Hidden)
+			},
+		},
+		{
+			name:       "hide article",
+			role:       rbac.RoleModerator,
+			body:       `{"action":"hide"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionArticleHide,
+			check: func(t *testing.T, articles *article.InMemoryArticle, _ *user.InMemoryUser, target uuid.UUID) {
+				got, _ := articles.GetArticleById(target)
+				assert.True(t, got.Hidden)
+			},
+		},
+		{
+			name:       "delete article",
+			role:       rbac.RoleModerator,
+			body:       `{"action":"delete"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionArticleDelete,
+			check: func(t *testing.T, articles *article.InMemoryArticle, _ *user.InMemoryUser, target uuid.UUID) {
+				_, err := articles.GetArticleById(target)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:       "warn the author of an article",
+			role:       rbac.RoleModerator,
+			body:       `{"action":"warn"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionUserWarn,
+			check: func(t *testing.T, articles *article.InMemoryArticle, users *user.InMemoryUser, target uuid.UUID) {
+				a, _ := articles.GetArticleById(target)
+				author, _ := users.GetUserById(a.AuthorId)
+				assert.Equal(t, 1, author.Warnings)
+			},
+		},
+		{
+			name:       "ban a reported user",
+			targetUser: true,
+			role:       rbac.RoleModerator,
+			body:       `{"action":"ban"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionUserBan,
+			check: func(t *testing.T, _ *article.InMemoryArticle, users *user.InMemoryUser, target uuid.UUID) {
+				got, _ := users.GetUserById(target)
+				ban := got.ActiveRestriction(user.RestrictionBan)
+				if assert.NotNil(t, ban) {
+					assert.Equal(t, "spam", ban.Reason, "the report reason stands in for a missing note")
+					assert.Nil(t, ban.ExpiresAt)
+				}
+			},
+		},
+		{
+			name:       "temporary ban",
+			targetUser: true,
+			role:       rbac.RoleModerator,
+			body:       `{"action":"ban","expires_at":"` + future + `"}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  audit.ActionUserBan,
+			check: func(t *testing.T, _ *article.InMemoryArticle, users *user.InMemoryUser, target uuid.UUID) {
+				got, _ := users.GetUserById(target)
+				ban := got.ActiveRestriction(user.RestrictionBan)
+				if assert.NotNil(t, ban) && assert.NotNil(t, ban.ExpiresAt) {
+					assert.Equal(t, future, ban.ExpiresAt.Format(time.RFC3339))
+				}
+			},
+		},
+		{
+			name:          "expiry in the past",
+			targetUser:    true,
+			role:          rbac.RoleModerator,
+			body:          `{"action":"ban","expires_at":"2000-01-01T00:00:00Z"}`,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "must be in the future",
+		},
+		{
+			name:          "expiry of a warning",
+			role:          rbac.RoleModerator,
+			body:          `{"action":"warn","expires_at":"` + future + `"}`,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "applies only to bans",
+		},
+		{
+			name:          "hide a user",
+			targetUser:    true,
+			role:          rbac.RoleModerator,
+			body:          `{"action":"hide"}`,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "hide and delete apply only to article reports",
+		},
+		{
+			name:          "unknown action",
+			role:          rbac.RoleModerator,
+			body:          `{"action":"burn"}`,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "must be one of dismiss, hide, delete, warn, ban",
+		},
+		{
+			name:          "action beyond the caller's permissions",
+			role:          rbac.RoleAuthor,
+			body:          `{"action":"ban"}`,
+			wantStatus:    http.StatusForbidden,
+			wantErrorText: "permission denied",
+		},
+		{
+			name:          "claimed by another moderator",
+			role:          rbac.RoleModerator,
+			claimedByPeer: true,
+			body:          `{"action":"dismiss"}`,
+			wantStatus:    http.StatusConflict,
+			wantErrorText: "report is claimed by another moderator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := user.NewInMemoryUser()
+			moderator, _ := users.CreateUser("moderator@mail.ru", "password1", "Moderator")
+			moderator, _ = users.GrantRole(moderator.Id, tt.role)
+			author, _ := users.CreateUser("author@mail.ru", "password1", "Author")
+			articles := article.NewInMemoryArticle()
+			reported, _ := articles.CreateArticle(article.Author{Id: author.Id}, "Спам", article.FormatMarkdown, "Текст", nil)
+			_, _ = articles.SetStatus(reported.Id, article.StatusPublished, nil)
+			reports := report.NewInMemoryReport()
+			auditLog := audit.NewInMemoryAuditLog()
+
+			targetType, target := report.TargetArticle, reported.Id
+			if tt.targetUser {
+				targetType, target = report.TargetUser, author.Id
+			}
+			pending, err := reports.CreateReport(targetType, target, uuid.New(), report.ReasonSpam, "")
+			require.NoError(t, err)
+			second, _ := reports.CreateReport(targetType, target, uuid.New(), report.ReasonAbuse, "")
+			if tt.claimedByPeer {
+				_, _ = reports.ClaimReport(pending.Id, uuid.New())
+			}
+
+			req := newRequest(http.MethodPost, "/api/v1/moderation/reports/"+pending.Id.String()+"/resolve", tt.body, moderator)
+			req.SetPathValue("id", pending.Id.String())
+			w := httptest.NewRecorder()
+
+			ResolveReportHandler(w, req, reports, articles, users, session.NewInMemorySession(), auditLog)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				assert.Empty(t, auditLog.Entries)
+				got, _ := reports.GetReportById(pending.Id)
+				assert.NotEqual(t, report.StatusResolved, got.Status)
+				return
+			}
+
+			var resolved []report.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resolved))
+			require.Len(t, resolved, 2)
+			assert.Equal(t, pending.Id, resolved[0].Id)
+			assert.Equal(t, second.Id, resolved[1].Id)
+
+			require.Len(t, auditLog.Entries, 1)
+			assert.Equal(t, tt.wantAudit, auditLog.Entries[0].Action)
+			assert.Equal(t, moderator.Id, auditLog.Entries[0].ActorId)
+			assert.Equal(t, pending.Id, *auditLog.Entries[0].ReportId)
+
+			tt.check(t, articles, users, target)
+		})
+	}
+}
+
+func TestResolveReportHandlerActsOnce(t *testing.T) {
+	users := user.NewInMemoryUser()
+	moderator, _ := users.CreateUser("moderator@mail.ru", "password1", "Moderator")
+	moderator, _ = users.GrantRole(moderator.Id, rbac.RoleModerator)
+	author, _ := users.CreateUser("author@mail.ru", "password1", "Author")
+	articles := article.NewInMemoryArticle()
+	reported, _ := articles.CreateArticle(article.Author{Id: author.Id}, "Спам", article.FormatMarkdown, "Текст", nil)
+	reports := report.NewInMemoryReport()
+	auditLog := audit.NewInMemoryAuditLog()
+	pending, _ := reports.CreateReport(report.TargetArticle, reported.Id, uuid.New(), report.ReasonSpam, "")
+
+	resolve := func(body string) int {
+		req := newRequest(http.MethodPost, "/api/v1/moderation/reports/"+pending.Id.String()+"/resolve", body, moderator)
+		req.SetPathValue("id", pending.Id.String())
+		w := httptest.NewRecorder()
+		ResolveReportHandler(w, req, reports, articles, users, session.NewInMemorySession(), auditLog)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, resolve(`{"action":"dismiss"}`))
+	assert.Equal(t, http.StatusConflict, resolve(`{"action":"hide"}`))
+	got, _ := articles.GetArticleById(reported.Id)
+	assert.False(t, got.Hidden, "a resolved report takes no further action")
+	assert.Len(t, auditLog.Entries, 1)
+}
+
+func TestListReportsHandler(t *testing.T) {
+	reports := report.NewInMemoryReport()
+	open, _ := reports.CreateReport(report.TargetArticle, uuid.New(), uuid.New(), report.ReasonSpam, "")
+	claimed, _ := reports.CreateReport(report.TargetArticle, uuid.New(), uuid.New(), report.ReasonSpam, "")
+	_, _ = reports.ClaimReport(claimed.Id, uuid.New())
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIds    []uuid.UUID
+	}{
+		{name: "open by default", wantStatus: http.StatusOK, wantIds: []uuid.UUID{open.Id}},
+		{name: "claimed", query: "?status=claimed", wantStatus: http.StatusOK, wantIds: []uuid.UUID{claimed.Id}},
+		{name: "resolved", query: "?status=resolved", wantStatus: http.StatusOK, wantIds: []uuid.UUID{}},
+		{name: "unknown status", query: "?status=lost", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(http.MethodGet, "/api/v1/moderation/reports"+tt.query, "", &user.User{})
+			w := httptest.NewRecorder()
+
+			ListReportsHandler(w, req, reports)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantIds == nil {
+				return
+			}
+			var list []report.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+			ids := make([]uuid.UUID, 0, len(list))
+			for _, r := range list {
+				ids = append(ids, r.Id)
+			}
+			assert.Equal(t, tt.wantIds, ids)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package moderation
+
+import (
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+const maxReasonLength = 500
+
+// RestrictionInput leaves ExpiresAt unset for a permanent restriction.
+type RestrictionInput struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RestrictionsResponse struct {
+	UserID       uuid.UUID          `json:"user_id"`
+	Restrictions []user.Restriction `json:"restrictions"`
+}
+
+var restrictionAuditActions = map[user.RestrictionKind]string{
+	user.RestrictionBan:        audit.ActionUserBan,
+	user.RestrictionSuspension: audit.ActionUserSuspend,
+	user.RestrictionShadowBan:  audit.ActionUserShadowBan,
+}
+
+// GetRestrictionsHandler lists the user's active restrictions.
+func GetRestrictionsHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	found, err := users.GetUserById(userID)
+	if err != nil {
+		json.WriteAppError(w, lookupError(err))
+		return
+	}
+	writeRestrictions(w, found)
+}
+
+func RestrictUserHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository,
+	sessions session.SessionRepository, auditLog audit.AuditLog) {
+	userID, kind, err := parseRestrictionTarget(r)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	input := new(RestrictionInput)
+	if err := json.Read(w, r, input); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if input.Reason == "" || utf8.RuneCountInString(input.Reason) > maxReasonLength {
+		json.WriteAppError(w, apperror.Validation("reason", "must be between 1 and 500 characters"))
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		json.WriteAppError(w, apperror.Validation("expires_at", "must be in the future"))
+		return
+	}
+
+	updated, err := restrict(r, users, sessions, userID, kind, input.Reason, input.ExpiresAt)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	record(r, auditLog, audit.Entry{
+		ActorId:    auth.FromContext(r.Context()).User.Id,
+		Action:     restrictionAuditActions[kind],
+		TargetType: "user",
+		TargetId:   userID,
+		Note:       input.Reason,
+	})
+	writeRestrictions(w, updated)
+}
+
+func LiftRestrictionHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository, auditLog audit.AuditLog) {
+	userID, kind, err := parseRestrictionTarget(r)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := auth.Require(r.Context(), rbac.UserBan); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+
+	updated, err := users.LiftRestriction(userID, kind)
+	if err != nil {
+		json.WriteAppError(w, lookupError(err))
+		return
+	}
+
+	record(r, auditLog, audit.Entry{
+		ActorId:    auth.FromContext(r.Context()).User.Id,
+		Action:     audit.ActionUserLift,
+		TargetType: "user",
+		TargetId:   userID,
+		Note:       string(kind),
+	})
+	writeRestrictions(w, updated)
+}
+
+// restrict checks that the caller may restrict the user, stores the
+// restriction and, for bans, ends every session of the user. Restricting
+// staff takes role.manage so moderators can't lock each other out.
+func restrict(r *http.Request, users user.UserRepository, sessions session.SessionRepository,
+	userID uuid.UUID, kind user.RestrictionKind, reason string, expiresAt *time.Time) (*user.User, error) {
+	if err := auth.Require(r.Context(), rbac.UserBan); err != nil {
+		return nil, err
+	}
+	actor := auth.FromContext(r.Context())
+	if actor.User.Id == userID {
+		return nil, apperror.New(apperror.CodeBadRequest, "you cannot restrict yourself")
+	}
+
+	target, err := users.GetUserById(userID)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	}
+	if rbac.Can(target.Roles, rbac.ReportReview) && !actor.Can(rbac.RoleManage) {
+		return nil, auth.ErrForbidden
+	}
+
+	updated, err := users.Restrict(userID, user.Restriction{
+		Kind:      kind,
+		Reason:    reason,
+		IssuedBy:  actor.User.Id,
+		IssuedAt:  time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	}
+
+	if kind == user.RestrictionBan {
+		if _, err := sessions.DeleteSessionsByUserId(userID); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+func parseRestrictionTarget(r *http.Request) (uuid.UUID, user.RestrictionKind, error) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, "", apperror.New(apperror.CodeBadRequest, "invalid user id")
+	}
+	kind, err := user.ParseRestrictionKind(r.PathValue("kind"))
+	if err != nil {
+		return uuid.Nil, "", apperror.New(apperror.CodeBadRequest, err.Error())
+	}
+	return userID, kind, nil
+}
+
+func writeRestrictions(w http.ResponseWriter, u *user.User) {
+	resp := RestrictionsResponse{UserID: u.Id, Restrictions: u.ActiveRestrictions()}
+	if err := json.Write(w, http.StatusOK, resp); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
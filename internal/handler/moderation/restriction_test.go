@@ -0,0 +1,112 @@
+package moderation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictUserHandler(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		actorRole     rbac.Role
+		target        string
+		kind          string
+		body          string
+		wantStatus    int
+		wantErrorText string
+		wantAudit     string
+		wantSessions  int
+	}{
+		{name: "temporary suspension", actorRole: rbac.RoleModerator, target: "member", kind: "suspension", body: `{"reason":"флуд","expires_at":"` + future + `"}`, wantStatus: http.StatusOK, wantAudit: audit.ActionUserSuspend, wantSessions: 2},
+		{name: "shadow-ban", actorRole: rbac.RoleModerator, target: "member", kind: "shadowban", body: `{"reason":"накрутка"}`, wantStatus: http.StatusOK, wantAudit: audit.ActionUserShadowBan, wantSessions: 2},
+		{name: "ban ends sessions", actorRole: rbac.RoleModerator, target: "member", kind: "ban", body: `{"reason":"спам"}`, wantStatus: http.StatusOK, wantAudit: audit.ActionUserBan, wantSessions: 0},
+		{name: "missing reason", actorRole: rbac.RoleModerator, target: "member", kind: "ban", body: `{}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be between 1 and 500 characters", wantSessions: 2},
+		{name: "expiry in the past", actorRole: rbac.RoleModerator, target: "member", kind: "ban", body: `{"reason":"спам","expires_at":"2000-01-01T00:00:00Z"}`, wantStatus: http.StatusBadRequest, wantErrorText: "must be in the future", wantSessions: 2},
+		{name: "unknown kind", actorRole: rbac.RoleModerator, target: "member", kind: "exile", body: `{"reason":"спам"}`, wantStatus: http.StatusBadRequest, wantErrorText: `unknown restriction "exile"`, wantSessions: 2},
+		{name: "self", actorRole: rbac.RoleModerator, target: "actor", kind: "ban", body: `{"reason":"спам"}`, wantStatus: http.StatusBadRequest, wantErrorText: "you cannot restrict yourself", wantSessions: 2},
+		{name: "moderator restricting staff", actorRole: rbac.RoleModerator, target: "staff", kind: "ban", body: `{"reason":"спам"}`, wantStatus: http.StatusForbidden, wantErrorText: "permission denied", wantSessions: 2},
+		{name: "admin restricting staff", actorRole: rbac.RoleAdmin, target: "staff", kind: "suspension", body: `{"reason":"отпуск"}`, wantStatus: http.StatusOK, wantAudit: audit.ActionUserSuspend, wantSessions: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := user.NewInMemoryUser()
+			actor, _ := users.CreateUser("actor@mail.ru", "password1", "Actor")
+			actor, _ = users.GrantRole(actor.Id, tt.actorRole)
+			member, _ := users.CreateUser("member@mail.ru", "password1", "Member")
+			staff, _ := users.CreateUser("staff@mail.ru", "password1", "Staff")
+			_, _ = users.GrantRole(staff.Id, rbac.RoleModerator)
+			targets := map[string]*user.User{"actor": actor, "member": member, "staff": staff}
+			target := targets[tt.target]
+
+			sessions := session.NewInMemorySession()
+			for range 2 {
+				s, _ := sessions.CreateSession()
+				_, _ = sessions.SetSessionUserId(s.SessionId, target.Id)
+			}
+			auditLog := audit.NewInMemoryAuditLog()
+
+			req := newRequest(http.MethodPut, "/api/v1/moderation/users/"+target.Id.String()+"/restrictions/"+tt.kind, tt.body, actor)
+			req.SetPathValue("id", target.Id.String())
+			req.SetPathValue("kind", tt.kind)
+			w := httptest.NewRecorder()
+
+			RestrictUserHandler(w, req, users, sessions, auditLog)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantSessions, sessions.Count())
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				assert.Empty(t, auditLog.Entries)
+				return
+			}
+
+			var resp RestrictionsResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, target.Id, resp.UserID)
+			require.Len(t, resp.Restrictions, 1)
+			assert.Equal(t, user.RestrictionKind(tt.kind), resp.Restrictions[0].Kind)
+			assert.Equal(t, actor.Id, resp.Restrictions[0].IssuedBy)
+
+			require.Len(t, auditLog.Entries, 1)
+			assert.Equal(t, tt.wantAudit, auditLog.Entries[0].Action)
+		})
+	}
+}
+
+func TestLiftRestrictionHandler(t *testing.T) {
+	users := user.NewInMemoryUser()
+	moderator, _ := users.CreateUser("moderator@mail.ru", "password1", "Moderator")
+	moderator, _ = users.GrantRole(moderator.Id, rbac.RoleModerator)
+	member, _ := users.CreateUser("member@mail.ru", "password1", "Member")
+	_, _ = users.Restrict(member.Id, user.Restriction{Kind: user.RestrictionSuspension, Reason: "флуд"})
+	auditLog := audit.NewInMemoryAuditLog()
+
+	req := newRequest(http.MethodDelete, "/api/v1/moderation/users/"+member.Id.String()+"/restrictions/suspension", "", moderator)
+	req.SetPathValue("id", member.Id.String())
+	req.SetPathValue("kind", "suspension")
+	w := httptest.NewRecorder()
+
+	LiftRestrictionHandler(w, req, users, auditLog)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	got, _ := users.GetUserById(member.Id)
+	assert.Nil(t, got.ActiveRestriction(user.RestrictionSuspension))
+	require.Len(t, auditLog.Entries, 1)
+	assert.Equal(t, audit.ActionUserLift, auditLog.Entries[0].Action)
+	assert.Equal(t, "suspension", auditLog.Entries[0].Note)
+}
@@ -9,8 +9,12 @@ import (
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
-	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/login"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
+	userrepo "github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
 )
 
@@ -20,58 +24,50 @@ type UserRegisterInput struct {
 	Name     string `json:"name"`
 }
 
-func RegistrationHandler(w http.ResponseWriter, r *http.Request, sessions *session.InMemorySession,
-	users *user.InMemoryUser) {
-	if r.Method != http.MethodPost {
-		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
+func RegistrationHandler(w http.ResponseWriter, r *http.Request, sessions session.SessionRepository,
+	users userrepo.UserRepository, protector *middleware.CSRF) {
 	newUserData := new(UserRegisterInput)
-	err := json.Read(r, newUserData)
+	err := json.Read(w, r, newUserData)
 	if err != nil {
-		json.WriteError(w, http.StatusInternalServerError, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
 
 	if err := validateEmail(newUserData.Email); err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, apperror.Validation("email", err.Error()))
 		return
 	}
 
 	if err := validatePassword(newUserData.Password); err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, apperror.Validation("password", err.Error()))
 		return
 	}
 
 	if err := validateName(newUserData.Name); err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, apperror.Validation("name", err.Error()))
 		return
 	}
 
 	user, err := users.CreateUser(newUserData.Email, newUserData.Password, newUserData.Name)
-	if err != nil {
-		json.WriteError(w, http.StatusConflict, err.Error())
+	if errors.Is(err, userrepo.ErrUserExists) {
+		json.WriteAppError(w, apperror.Wrap(apperror.CodeConflict, err.Error(), err))
 		return
 	}
-
-	session, err := sessions.CreateSession()
 	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
+	logger.SetUserID(r.Context(), user.Id.String())
+	metrics.Registrations.Inc()
 
-	cookies.SetCookie(w, session.SessionId)
-
-	_, err = sessions.SetSessionUserId(session.SessionId, user.Id)
-	if err != nil {
-		json.WriteError(w, http.StatusBadRequest, err.Error())
+	if err := login.StartSession(w, r, sessions, protector, user.Id); err != nil {
+		json.WriteAppError(w, err)
 		return
 	}
 
 	err = json.Write(w, http.StatusCreated, user)
 	if err != nil {
-		json.WriteError(w, http.StatusInternalServerError, err.Error())
+		json.WriteAppError(w, err)
 		return
 	}
 }
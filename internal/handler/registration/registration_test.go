@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 	"github.com/stretchr/testify/assert"
@@ -96,6 +97,7 @@ func TestRegistrationStatus(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/registration", bytes.NewBufferString(test.body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			sessions := session.NewInMemorySession()
@@ -105,7 +107,7 @@ func TestRegistrationStatus(t *testing.T) {
 				_, _ = users.CreateUser("dup@mail.com", "1234", "user")
 			}
 
-			RegistrationHandler(w, req, sessions, users)
+			RegistrationHandler(w, req, sessions, users, middleware.NewCSRF([]byte("secret"), nil))
 
 			resp := w.Result()
 			assert.Equal(t, test.wantStatus, resp.StatusCode, "status code mismatch in case %s", test.name)
@@ -158,12 +160,13 @@ func TestRegistrationResponse(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/registration", bytes.NewBufferString(test.body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			sessions := session.NewInMemorySession()
 			users := user.NewInMemoryUser()
 
-			RegistrationHandler(w, req, sessions, users)
+			RegistrationHandler(w, req, sessions, users, middleware.NewCSRF([]byte("secret"), nil))
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -0,0 +1,203 @@
+package relation
+
+import (
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+const (
+	minKeywordLength = 2
+	maxKeywordLength = 50
+)
+
+type BlocksResponse struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+type MutesResponse struct {
+	UserIDs  []uuid.UUID `json:"user_ids"`
+	Keywords []string    `json:"keywords"`
+}
+
+type FollowsResponse struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+func GetBlocksHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	writeBlocks(w, r, relations)
+}
+
+func BlockHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository, relations relation.RelationRepository) {
+	targetID, err := parseTarget(r, users)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := relations.Block(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeBlocks(w, r, relations)
+}
+
+// UnblockHandler doesn't check that the user exists, so blocks of deleted
+// users can still be cleaned up.
+func UnblockHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if err := relations.Unblock(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeBlocks(w, r, relations)
+}
+
+func GetMutesHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	writeMutes(w, r, relations)
+}
+
+func MuteUserHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository, relations relation.RelationRepository) {
+	targetID, err := parseTarget(r, users)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := relations.MuteUser(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeMutes(w, r, relations)
+}
+
+func UnmuteUserHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if err := relations.UnmuteUser(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeMutes(w, r, relations)
+}
+
+func MuteKeywordHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	keyword := relation.NormalizeKeyword(r.PathValue("keyword"))
+	if length := utf8.RuneCountInString(keyword); length < minKeywordLength || length > maxKeywordLength {
+		json.WriteAppError(w, apperror.Validation("keyword", "must be between 2 and 50 characters"))
+		return
+	}
+
+	err := relations.MuteKeyword(currentUserID(r), keyword)
+	if errors.Is(err, relation.ErrMuteLimit) {
+		json.WriteAppError(w, apperror.Wrap(apperror.CodeConflict, "you can mute at most 100 keywords", err))
+		return
+	}
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeMutes(w, r, relations)
+}
+
+func UnmuteKeywordHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	if err := relations.UnmuteKeyword(currentUserID(r), r.PathValue("keyword")); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeMutes(w, r, relations)
+}
+
+func GetFollowsHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	writeFollows(w, r, relations)
+}
+
+func FollowHandler(w http.ResponseWriter, r *http.Request, users user.UserRepository, relations relation.RelationRepository) {
+	targetID, err := parseTarget(r, users)
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := relations.Follow(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeFollows(w, r, relations)
+}
+
+// UnfollowHandler, like UnblockHandler, works for deleted users too.
+func UnfollowHandler(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		json.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if err := relations.Unfollow(currentUserID(r), targetID); err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	writeFollows(w, r, relations)
+}
+
+func parseTarget(r *http.Request, users user.UserRepository) (uuid.UUID, error) {
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, apperror.New(apperror.CodeBadRequest, "invalid user id")
+	}
+	if targetID == currentUserID(r) {
+		return uuid.Nil, apperror.New(apperror.CodeBadRequest, "you cannot block, mute or follow yourself")
+	}
+	if _, err := users.GetUserById(targetID); err != nil {
+		return uuid.Nil, apperror.Wrap(apperror.CodeNotFound, err.Error(), err)
+	}
+	return targetID, nil
+}
+
+func currentUserID(r *http.Request) uuid.UUID {
+	return auth.FromContext(r.Context()).User.Id
+}
+
+func writeBlocks(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	rel, err := relations.GetRelations(currentUserID(r))
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := json.Write(w, http.StatusOK, BlocksResponse{UserIDs: rel.Blocked}); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+func writeMutes(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	rel, err := relations.GetRelations(currentUserID(r))
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := json.Write(w, http.StatusOK, MutesResponse{UserIDs: rel.MutedUsers, Keywords: rel.MutedKeywords}); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
+
+func writeFollows(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository) {
+	rel, err := relations.GetRelations(currentUserID(r))
+	if err != nil {
+		json.WriteAppError(w, err)
+		return
+	}
+	if err := json.Write(w, http.StatusOK, FollowsResponse{UserIDs: rel.Following}); err != nil {
+		json.WriteAppError(w, err)
+	}
+}
@@ -0,0 +1,146 @@
+package relation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func TestRelationHandlers(t *testing.T) {
+	users := user.NewInMemoryUser()
+	viewer, _ := users.CreateUser("viewer@mail.ru", "password1", "Viewer")
+	target, _ := users.CreateUser("target@mail.ru", "password1", "Target")
+
+	tests := []struct {
+		name          string
+		handler       func(w http.ResponseWriter, r *http.Request, relations relation.RelationRepository)
+		id            string
+		keyword       string
+		wantStatus    int
+		wantErrorText string
+		wantBody      string
+	}{
+		{
+			name: "block",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				BlockHandler(w, r, users, rel)
+			},
+			id:         target.Id.String(),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"user_ids":["` + target.Id.String() + `"]}`,
+		},
+		{
+			name: "block yourself",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				BlockHandler(w, r, users, rel)
+			},
+			id:            viewer.Id.String(),
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "you cannot block, mute or follow yourself",
+		},
+		{
+			name: "block unknown user",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				BlockHandler(w, r, users, rel)
+			},
+			id:            uuid.NewString(),
+			wantStatus:    http.StatusNotFound,
+			wantErrorText: "user not found",
+		},
+		{
+			name:          "unblock invalid id",
+			handler:       UnblockHandler,
+			id:            "42",
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "invalid user id",
+		},
+		{
+			name: "mute user",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				MuteUserHandler(w, r, users, rel)
+			},
+			id:         target.Id.String(),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"user_ids":["` + target.Id.String() + `"],"keywords":[]}`,
+		},
+		{
+			name:       "mute keyword",
+			handler:    MuteKeywordHandler,
+			keyword:    " Крипта ",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"user_ids":[],"keywords":["крипта"]}`,
+		},
+		{
+			name: "follow",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				FollowHandler(w, r, users, rel)
+			},
+			id:         target.Id.String(),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"user_ids":["` + target.Id.String() + `"]}`,
+		},
+		{
+			name: "follow yourself",
+			handler: func(w http.ResponseWriter, r *http.Request, rel relation.RelationRepository) {
+				FollowHandler(w, r, users, rel)
+			},
+			id:            viewer.Id.String(),
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "you cannot block, mute or follow yourself",
+		},
+		{
+			name:       "unfollow",
+			handler:    UnfollowHandler,
+			id:         target.Id.String(),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"user_ids":[]}`,
+		},
+		{
+			name:          "mute too short keyword",
+			handler:       MuteKeywordHandler,
+			keyword:       "a",
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "must be between 2 and 50 characters",
+		},
+		{
+			name:          "mute too long keyword",
+			handler:       MuteKeywordHandler,
+			keyword:       strings.Repeat("я", 51),
+			wantStatus:    http.StatusBadRequest,
+			wantErrorText: "must be between 2 and 50 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/me/blocks/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			req.SetPathValue("keyword", tt.keyword)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{SessionID: uuid.New(), User: viewer}))
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req, relation.NewInMemoryRelation())
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantErrorText != "" {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantErrorText, resp.Error)
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
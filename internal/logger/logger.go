@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as substrings of lower-cased attribute keys.
+var sensitiveKeys = []string{"password", "cookie", "authorization", "token", "secret"}
+
+func New(w io.Writer, format string, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, errors.New("log level must be debug, info, warn or error")
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redact,
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+	return nil, errors.New("log format must be json or text")
+}
+
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+	return attr
+}
+
+type ctxKey struct{}
+
+// requestState is shared by everything handling one request, so a handler
+// deep in the chain can tell the access log who the user was.
+type requestState struct {
+	logger *slog.Logger
+	userID string
+	mu     sync.Mutex
+}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestState{logger: logger})
+}
+
+// FromContext returns the request-scoped logger, or slog.Default outside a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if state, ok := ctx.Value(ctxKey{}).(*requestState); ok {
+		return state.logger
+	}
+	return slog.Default()
+}
+
+func SetUserID(ctx context.Context, userID string) {
+	if state, ok := ctx.Value(ctxKey{}).(*requestState); ok {
+		state.mu.Lock()
+		state.userID = userID
+		state.mu.Unlock()
+	}
+}
+
+func UserID(ctx context.Context) string {
+	if state, ok := ctx.Value(ctxKey{}).(*requestState); ok {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		return state.userID
+	}
+	return ""
+}
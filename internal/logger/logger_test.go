@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr string
+	}{
+		{name: "json", format: "json", level: "info"},
+		{name: "text", format: "text", level: "debug"},
+		{name: "unknown format", format: "xml", level: "info", wantErr: "log format must be json or text"},
+		{name: "unknown level", format: "json", level: "loud", wantErr: "log level must be debug, info, warn or error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := New(&bytes.Buffer{}, tt.format, tt.level)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, l)
+		})
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "json", "info")
+	assert.NoError(t, err)
+
+	l.Info("login", "password", "123", "Cookie", "session_id=abc", "csrf_token", "t", "email", "user@mail.com")
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "[REDACTED]", entry["password"])
+	assert.Equal(t, "[REDACTED]", entry["Cookie"])
+	assert.Equal(t, "[REDACTED]", entry["csrf_token"])
+	assert.Equal(t, "user@mail.com", entry["email"])
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+	assert.Empty(t, UserID(context.Background()))
+
+	l := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	ctx := WithLogger(context.Background(), l)
+	SetUserID(ctx, "42")
+
+	assert.Equal(t, l, FromContext(ctx))
+	assert.Equal(t, "42", UserID(ctx))
+}
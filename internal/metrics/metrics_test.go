@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	handler := Instrument("/feed", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, float64(1), testutil.ToFloat64(httpInFlight.WithLabelValues("/feed")))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, path := range []string{"/feed", "/feed?page=2", "/feed/../feed"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(httpRequests.WithLabelValues("/feed", "get", "418")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(httpInFlight.WithLabelValues("/feed")))
+	assert.Equal(t, 1, testutil.CollectAndCount(httpDuration))
+}
+
+func TestHandler(t *testing.T) {
+	SetActiveSessionsSource(func() int { return 7 })
+	Registrations.Inc()
+	LoginFailures.WithLabelValues("invalid_password").Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	body := string(data)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "mindleak_active_sessions 7")
+	assert.Contains(t, body, "mindleak_registrations_total 1")
+	assert.Contains(t, body, `mindleak_login_failures_total{reason="invalid_password"} 1`)
+	assert.True(t, strings.Contains(body, "go_goroutines"), "runtime metrics must be exported")
+}
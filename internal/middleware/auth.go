@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/tracing"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+	"github.com/google/uuid"
+)
+
+type Auth struct {
+	sessions session.SessionRepository
+	users    user.UserRepository
+}
+
+func NewAuth(sessions session.SessionRepository, users user.UserRepository) *Auth {
+	return &Auth{sessions: sessions, users: users}
+}
+
+// RequireAuth rejects requests without a logged-in user with a 401.
+func (a *Auth) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := a.resolve(r)
+		if !principal.Authenticated() {
+			json.WriteAppError(w, auth.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// OptionalAuth stores the principal when the session is valid and lets every
+// request through; handlers check auth.FromContext for nil.
+func (a *Auth) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal := a.resolve(r); principal != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission runs after RequireAuth or OptionalAuth and answers 401
+// or 403 via auth.Require.
+func RequirePermission(permission rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := auth.Require(r.Context(), permission); err != nil {
+				json.WriteAppError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RejectSuspended makes the account of a suspended user read-only: safe
+// methods pass, everything else gets a 403 naming the expiry. It runs after
+// RequireAuth or OptionalAuth.
+func RejectSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal.Authenticated() && !isSafeMethod(r.Method) {
+			if suspension := principal.User.ActiveRestriction(user.RestrictionSuspension); suspension != nil {
+				json.WriteAppError(w, apperror.New(apperror.CodeForbidden,
+					fmt.Sprintf("account is suspended %s: %s", suspension.Until(), suspension.Reason)))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resolve returns nil for a missing or unknown session. A session whose user
+// no longer exists or has been banned is treated as anonymous.
+func (a *Auth) resolve(r *http.Request) *auth.Principal {
+	cookie, err := cookies.GetCookie(r)
+	if err != nil {
+		return nil
+	}
+	sessionID, err := uuid.Parse(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	s, err := tracing.Sessions(r.Context(), a.sessions).GetSessionById(sessionID)
+	if err != nil {
+		return nil
+	}
+
+	principal := &auth.Principal{SessionID: s.SessionId}
+	if s.UserId == uuid.Nil {
+		return principal
+	}
+	if u, err := tracing.Users(r.Context(), a.users).GetUserById(s.UserId); err == nil && u.ActiveRestriction(user.RestrictionBan) == nil {
+		principal.User = u
+		logger.SetUserID(r.Context(), u.Id.String())
+	}
+	return principal
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth(t *testing.T) {
+	sessions := session.NewInMemorySession()
+	users := user.NewInMemoryUser()
+
+	u, _ := users.CreateUser("user@mail.com", "password1", "TestUser")
+	loggedIn, _ := sessions.CreateSession()
+	_, _ = sessions.SetSessionUserId(loggedIn.SessionId, u.Id)
+	anonymous, _ := sessions.CreateSession()
+	orphaned, _ := sessions.CreateSession()
+	_, _ = sessions.SetSessionUserId(orphaned.SessionId, uuid.New())
+	b, _ := users.CreateUser("banned@mail.com", "password1", "Banned")
+	_, _ = users.Restrict(b.Id, user.Restriction{Kind: user.RestrictionBan, Reason: "spam"})
+	banned, _ := sessions.CreateSession()
+	_, _ = sessions.SetSessionUserId(banned.SessionId, b.Id)
+
+	tests := []struct {
+		name             string
+		cookie           string
+		wantSession      uuid.UUID
+		wantUser         bool
+		wantRequireCode  int
+		wantHasPrincipal bool
+	}{
+		{name: "no cookie", wantRequireCode: http.StatusUnauthorized},
+		{name: "unsigned cookie", cookie: loggedIn.SessionId.String(), wantRequireCode: http.StatusUnauthorized},
+		{name: "not a uuid", cookie: cookies.Encode("not-a-uuid"), wantRequireCode: http.StatusUnauthorized},
+		{name: "unknown session", cookie: cookies.Encode(uuid.NewString()), wantRequireCode: http.StatusUnauthorized},
+		{name: "anonymous session", cookie: cookies.Encode(anonymous.SessionId.String()), wantSession: anonymous.SessionId, wantHasPrincipal: true, wantRequireCode: http.StatusUnauthorized},
+		{name: "session of deleted user", cookie: cookies.Encode(orphaned.SessionId.String()), wantSession: orphaned.SessionId, wantHasPrincipal: true, wantRequireCode: http.StatusUnauthorized},
+		{name: "session of banned user", cookie: cookies.Encode(banned.SessionId.String()), wantSession: banned.SessionId, wantHasPrincipal: true, wantRequireCode: http.StatusUnauthorized},
+		{name: "logged in", cookie: cookies.Encode(loggedIn.SessionId.String()), wantSession: loggedIn.SessionId, wantUser: true, wantHasPrincipal: true, wantRequireCode: http.StatusOK},
+	}
+
+	authn := NewAuth(sessions, users)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newRequest := func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+				if tt.cookie != "" {
+					req.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: tt.cookie})
+				}
+				return req
+			}
+
+			var principal *auth.Principal
+			capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal = auth.FromContext(r.Context())
+			})
+
+			w := httptest.NewRecorder()
+			authn.OptionalAuth(capture).ServeHTTP(w, newRequest())
+			assert.Equal(t, http.StatusOK, w.Code, "optional auth never rejects")
+			if tt.wantHasPrincipal {
+				if assert.NotNil(t, principal) {
+					assert.Equal(t, tt.wantSession, principal.SessionID)
+					assert.Equal(t, tt.wantUser, principal.Authenticated())
+				}
+			} else {
+				assert.Nil(t, principal)
+			}
+
+			principal = nil
+			w = httptest.NewRecorder()
+			authn.RequireAuth(capture).ServeHTTP(w, newRequest())
+			assert.Equal(t, tt.wantRequireCode, w.Code)
+			if tt.wantRequireCode == http.StatusUnauthorized {
+				assert.Nil(t, principal, "handler must not run")
+				assert.JSONEq(t, `{"error":"authentication required","code":"unauthorized"}`, w.Body.String())
+			} else {
+				assert.Equal(t, u.Id, principal.User.Id)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{name: "anonymous", wantStatus: http.StatusUnauthorized},
+		{name: "anonymous session", principal: &auth.Principal{SessionID: uuid.New()}, wantStatus: http.StatusUnauthorized},
+		{name: "missing permission", principal: &auth.Principal{User: &user.User{Roles: []rbac.Role{rbac.RoleUser}}}, wantStatus: http.StatusForbidden},
+		{name: "granted", principal: &auth.Principal{User: &user.User{Roles: []rbac.Role{rbac.RoleModerator}}}, wantStatus: http.StatusOK},
+	}
+
+	handler := RequirePermission(rbac.UserBan)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRejectSuspended(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	suspended := &user.User{Restrictions: []user.Restriction{{Kind: user.RestrictionSuspension, Reason: "flood", ExpiresAt: &future}}}
+	expired := &user.User{Restrictions: []user.Restriction{{Kind: user.RestrictionSuspension, ExpiresAt: &past}}}
+
+	tests := []struct {
+		name       string
+		method     string
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{name: "anonymous", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "active user", method: http.MethodPost, principal: &auth.Principal{User: &user.User{}}, wantStatus: http.StatusOK},
+		{name: "suspended user reads", method: http.MethodGet, principal: &auth.Principal{User: suspended}, wantStatus: http.StatusOK},
+		{name: "suspended user writes", method: http.MethodPost, principal: &auth.Principal{User: suspended}, wantStatus: http.StatusForbidden},
+		{name: "expired suspension", method: http.MethodDelete, principal: &auth.Principal{User: expired}, wantStatus: http.StatusOK},
+	}
+
+	handler := RejectSuspended(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), "account is suspended until "+future.UTC().Format(time.RFC3339)+": flood")
+			}
+		})
+	}
+}
@@ -1,22 +1,127 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
-func CORSMiddleware(next http.Handler) http.Handler {
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+// CORSConfig origins may use a leading wildcard subdomain, e.g. "https://*.mindleak.ru".
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           int
+	AllowCredentials bool
+}
+
+type CORS struct {
+	cfg     CORSConfig
+	methods map[string]bool
+	headers map[string]bool
+}
+
+func NewCORS(cfg CORSConfig) *CORS {
+	c := &CORS{
+		cfg:     cfg,
+		methods: make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+	for _, method := range cfg.AllowedMethods {
+		c.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		c.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	return c
+}
+
+func (c *CORS) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
 		origin := r.Header.Get("Origin")
-		if origin == "http://localhost:3000" || origin == "http://localhost:5173" || origin == "http://127.0.0.1:3000" || origin == "http://62.109.19.84:8080" {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-		}
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+		if preflight {
+			c.handlePreflight(w, r, origin)
 			return
 		}
 
+		if origin != "" && OriginAllowed(c.cfg.AllowedOrigins, origin) {
+			c.setOriginHeaders(w, origin)
+			if len(c.cfg.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.cfg.ExposedHeaders, ", "))
+			}
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (c *CORS) handlePreflight(w http.ResponseWriter, r *http.Request, origin string) {
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if origin == "" || !OriginAllowed(c.cfg.AllowedOrigins, origin) {
+		json.WriteError(w, http.StatusForbidden, "origin not allowed")
+		return
+	}
+
+	if !c.methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] {
+		json.WriteError(w, http.StatusForbidden, "method not allowed")
+		return
+	}
+
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !c.headers[http.CanonicalHeaderKey(header)] {
+			json.WriteError(w, http.StatusForbidden, "header not allowed")
+			return
+		}
+	}
+
+	c.setOriginHeaders(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.cfg.AllowedMethods, ", "))
+	if len(c.cfg.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.cfg.AllowedHeaders, ", "))
+	}
+	if c.cfg.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.cfg.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CORS) setOriginHeaders(w http.ResponseWriter, origin string) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.cfg.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func OriginAllowed(patterns []string, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if pattern == origin {
+			return true
+		}
+
+		p, err := url.Parse(strings.Replace(pattern, "*.", "wildcard.", 1))
+		if err != nil || !strings.HasPrefix(p.Host, "wildcard.") {
+			continue
+		}
+		suffix := strings.TrimPrefix(p.Host, "wildcard")
+		if p.Scheme == u.Scheme && strings.HasSuffix(u.Host, suffix) && len(u.Host) > len(suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cors := NewCORS(CORSConfig{
+		AllowedOrigins:   []string{"http://localhost:3000", "https://*.mindleak.ru"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type", CSRFHeader},
+		ExposedHeaders:   []string{"Retry-After"},
+		MaxAge:           600,
+		AllowCredentials: true,
+	})
+	handler := cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name            string
+		method          string
+		origin          string
+		requestMethod   string
+		requestHeaders  string
+		wantStatus      int
+		wantAllowOrigin string
+		wantMaxAge      string
+	}{
+		{
+			name:            "simple request from allowed origin",
+			method:          http.MethodGet,
+			origin:          "http://localhost:3000",
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "http://localhost:3000",
+		},
+		{
+			name:       "simple request from unknown origin",
+			method:     http.MethodGet,
+			origin:     "http://evil.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "wildcard subdomain",
+			method:          http.MethodGet,
+			origin:          "https://app.mindleak.ru",
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "https://app.mindleak.ru",
+		},
+		{
+			name:       "wildcard does not match bare suffix",
+			method:     http.MethodGet,
+			origin:     "https://evilmindleak.ru",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wildcard does not match other scheme",
+			method:     http.MethodGet,
+			origin:     "http://app.mindleak.ru",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "preflight allowed",
+			method:          http.MethodOptions,
+			origin:          "http://localhost:3000",
+			requestMethod:   http.MethodPost,
+			requestHeaders:  "content-type, x-csrf-token",
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "http://localhost:3000",
+			wantMaxAge:      "600",
+		},
+		{
+			name:          "preflight from unknown origin",
+			method:        http.MethodOptions,
+			origin:        "http://evil.com",
+			requestMethod: http.MethodPost,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "preflight with disallowed method",
+			method:        http.MethodOptions,
+			origin:        "http://localhost:3000",
+			requestMethod: http.MethodDelete,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:           "preflight with disallowed header",
+			method:         http.MethodOptions,
+			origin:         "http://localhost:3000",
+			requestMethod:  http.MethodPost,
+			requestHeaders: "X-Secret",
+			wantStatus:     http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/feed", nil)
+			req.Header.Set("Origin", test.origin)
+			if test.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", test.requestMethod)
+			}
+			if test.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", test.requestHeaders)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+			assert.Equal(t, test.wantAllowOrigin, resp.Header.Get("Access-Control-Allow-Origin"))
+			assert.Contains(t, resp.Header.Values("Vary"), "Origin")
+			assert.Equal(t, test.wantMaxAge, resp.Header.Get("Access-Control-Max-Age"))
+			if test.wantAllowOrigin != "" {
+				assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+const CSRFHeader = "X-CSRF-Token"
+
+// CSRF issues synchronizer tokens derived from the session id, so a token is
+// only valid together with the session cookie it was issued for.
+type CSRF struct {
+	secret  []byte
+	origins []string
+}
+
+func NewCSRF(secret []byte, origins []string) *CSRF {
+	return &CSRF{
+		secret:  secret,
+		origins: origins,
+	}
+}
+
+func (c *CSRF) Token(sessionID string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !c.trustedOrigin(r) {
+			json.WriteError(w, http.StatusForbidden, "origin not allowed")
+			return
+		}
+
+		cookie, err := cookies.GetCookie(r)
+		if err != nil {
+			json.WriteError(w, http.StatusForbidden, "csrf token missing")
+			return
+		}
+
+		token := r.Header.Get(CSRFHeader)
+		if token == "" {
+			json.WriteError(w, http.StatusForbidden, "csrf token missing")
+			return
+		}
+
+		if !hmac.Equal([]byte(token), []byte(c.Token(cookie.Value))) {
+			json.WriteError(w, http.StatusForbidden, "csrf token invalid")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// trustedOrigin checks Origin, falling back to Referer. Requests carrying
+// neither come from non-browser clients and are left to the token check.
+func (c *CSRF) trustedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			return true
+		}
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Host == r.Host {
+		return true
+	}
+	return OriginAllowed(c.origins, origin)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
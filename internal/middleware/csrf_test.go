@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	protector := NewCSRF([]byte("secret"), []string{"http://localhost:3000"})
+	sessionID := "0b6f1b4e-8d4f-4f6a-9d55-5e0f2b1e9c11"
+
+	handler := protector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		referer    string
+		setCookie  bool
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "safe method passes without token",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing cookie",
+			method:     http.MethodPost,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing token",
+			method:     http.MethodPost,
+			setCookie:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "token for another session",
+			method:     http.MethodPost,
+			setCookie:  true,
+			token:      protector.Token("another-session"),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "valid token",
+			method:     http.MethodPost,
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "valid token from allowed origin",
+			method:     http.MethodPost,
+			origin:     "http://localhost:3000",
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "valid token from same origin",
+			method:     http.MethodPost,
+			origin:     "http://example.com",
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "foreign origin",
+			method:     http.MethodPost,
+			origin:     "http://evil.com",
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "foreign referer",
+			method:     http.MethodPost,
+			referer:    "http://evil.com/page",
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "allowed referer",
+			method:     http.MethodPost,
+			referer:    "http://localhost:3000/login",
+			setCookie:  true,
+			token:      protector.Token(sessionID),
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "http://example.com/login", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			if test.referer != "" {
+				req.Header.Set("Referer", test.referer)
+			}
+			if test.setCookie {
+				req.AddCookie(&http.Cookie{Name: cookies.SessionID, Value: cookies.Encode(sessionID)})
+			}
+			if test.token != "" {
+				req.Header.Set(CSRFHeader, test.token)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+		status        int
+		wantLevel     string
+	}{
+		{name: "propagates request id", requestID: "abc-123", wantRequestID: "abc-123", status: http.StatusOK, wantLevel: "INFO"},
+		{name: "generates missing request id", status: http.StatusOK, wantLevel: "INFO"},
+		{name: "replaces malformed request id", requestID: "bad id\n", status: http.StatusOK, wantLevel: "INFO"},
+		{name: "server errors are logged as errors", status: http.StatusInternalServerError, wantLevel: "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			var ctxLogger *slog.Logger
+			handler := LoggingMiddleware(base)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxLogger = logger.FromContext(r.Context())
+				logger.SetUserID(r.Context(), "user-1")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("hello"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			requestID := w.Result().Header.Get(RequestIDHeader)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				_, err := uuid.Parse(requestID)
+				assert.NoError(t, err, "generated request id must be a UUID")
+			}
+			assert.NotEqual(t, base, ctxLogger, "handler must get a request-scoped logger")
+
+			var entry map[string]interface{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tt.wantLevel, entry["level"])
+			assert.Equal(t, requestID, entry["request_id"])
+			assert.Equal(t, "GET", entry["method"])
+			assert.Equal(t, "/feed", entry["path"])
+			assert.Equal(t, float64(tt.status), entry["status"])
+			assert.Equal(t, float64(5), entry["bytes"])
+			assert.Equal(t, "user-1", entry["user_id"])
+			assert.Contains(t, entry, "latency")
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+}
+
+// RateLimitStore keeps the counters; in-memory for now, Redis can implement the same interface.
+type RateLimitStore interface {
+	Allow(key string, limit RateLimit) (RateLimitResult, error)
+}
+
+type KeyFunc func(r *http.Request) string
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+type InMemoryRateLimitStore struct {
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+const rateLimitCleanupInterval = time.Minute
+
+func NewInMemoryRateLimitStore() *InMemoryRateLimitStore {
+	return &InMemoryRateLimitStore{
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Allow implements a token bucket: the bucket holds up to limit.Requests tokens
+// and refills at limit.Requests per limit.Window.
+func (mem *InMemoryRateLimitStore) Allow(key string, limit RateLimit) (RateLimitResult, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	now := mem.now()
+	mem.cleanup(now)
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Window.Seconds()
+
+	b, exists := mem.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, last: now, window: limit.Window}
+		mem.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := RateLimitResult{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	}
+	result.Remaining = int(b.tokens)
+
+	if b.tokens < 1 {
+		result.ResetAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	return result, nil
+}
+
+func (mem *InMemoryRateLimitStore) cleanup(now time.Time) {
+	if now.Sub(mem.lastCleanup) < rateLimitCleanupInterval {
+		return
+	}
+	for key, b := range mem.buckets {
+		if now.Sub(b.last) >= b.window {
+			delete(mem.buckets, key)
+		}
+	}
+	mem.lastCleanup = now
+}
+
+// RateLimitMiddleware panics on a limit without requests or window: limits
+// are set up once at startup, and a zero window would divide by zero.
+func RateLimitMiddleware(store RateLimitStore, limit RateLimit, keyFunc KeyFunc) func(http.Handler) http.Handler {
+	if limit.Requests <= 0 || limit.Window <= 0 {
+		panic(fmt.Sprintf("rate limit needs positive requests and window, got %d per %s", limit.Requests, limit.Window))
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// the route pattern, not the path: /articles/{id}/report is one
+			// bucket whatever the id, or changing the target would reset it
+			route := r.Pattern
+			if route == "" {
+				route = r.URL.Path
+			}
+			result, err := store.Allow(route+"|"+keyFunc(r), limit)
+			if err != nil {
+				// fail open: a broken limiter backend must not take the API down
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resetSeconds := int(math.Ceil(result.ResetAfter.Seconds()))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+				json.WriteError(w, http.StatusTooManyRequests, "too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func KeyByIP(r *http.Request) string {
+	return "ip:" + ClientIP(r)
+}
+
+func KeyBySession(r *http.Request) string {
+	cookie, err := cookies.GetCookie(r)
+	if err != nil || cookie.Value == "" {
+		return KeyByIP(r)
+	}
+	return "session:" + cookie.Value
+}
+
+// KeyByPrincipal keys by the principal the auth middleware already resolved,
+// so it must run after OptionalAuth or RequireAuth. Requests without a valid
+// session share their IP's bucket: unknown sessions are free to mint.
+func KeyByPrincipal(r *http.Request) string {
+	principal := auth.FromContext(r.Context())
+	if principal.Authenticated() {
+		return "user:" + principal.User.Id.String()
+	}
+	if principal != nil {
+		return "session:" + principal.SessionID.String()
+	}
+	return KeyByIP(r)
+}
+
+type clientIPKey struct{}
+
+// ParseTrustedProxies accepts addresses ("10.0.0.5") and networks
+// ("172.16.0.0/12").
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// RealIP resolves the client address once per request. X-Real-IP is taken
+// only from the trusted proxies: anyone else could rotate it to get a fresh
+// rate limit bucket on every request. With no trusted proxies, the first
+// request carrying the header logs a warning: behind a proxy every client
+// would share the proxy's rate limit buckets.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	var warnOnce sync.Once
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if header := strings.TrimSpace(r.Header.Get("X-Real-IP")); header != "" {
+				if isTrusted(ip, trusted) {
+					ip = header
+				} else if len(trusted) == 0 {
+					warnOnce.Do(func() {
+						slog.Warn("X-Real-IP ignored because server.trusted_proxies is empty; behind a proxy all clients share its rate limits",
+							"proxy", ip)
+					})
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// ClientIP is the address resolved by RealIP, or the connecting address
+// when the request didn't pass through it.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
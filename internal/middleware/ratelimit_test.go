@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryRateLimitStore(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, mem *InMemoryRateLimitStore, clock *time.Time)
+	}{
+		{
+			name: "allows up to the limit then rejects",
+			run: func(t *testing.T, mem *InMemoryRateLimitStore, clock *time.Time) {
+				limit := RateLimit{Requests: 3, Window: time.Minute}
+				for i := 0; i < 3; i++ {
+					res, err := mem.Allow("ip:1", limit)
+					assert.NoError(t, err)
+					assert.True(t, res.Allowed)
+					assert.Equal(t, 2-i, res.Remaining)
+				}
+				res, _ := mem.Allow("ip:1", limit)
+				assert.False(t, res.Allowed)
+				assert.Equal(t, 0, res.Remaining)
+				assert.Equal(t, 20*time.Second, res.ResetAfter)
+			},
+		},
+		{
+			name: "refills over time",
+			run: func(t *testing.T, mem *InMemoryRateLimitStore, clock *time.Time) {
+				limit := RateLimit{Requests: 2, Window: time.Minute}
+				_, _ = mem.Allow("ip:1", limit)
+				_, _ = mem.Allow("ip:1", limit)
+				res, _ := mem.Allow("ip:1", limit)
+				assert.False(t, res.Allowed)
+
+				*clock = clock.Add(30 * time.Second)
+				res, _ = mem.Allow("ip:1", limit)
+				assert.True(t, res.Allowed)
+			},
+		},
+		{
+			name: "keys are independent",
+			run: func(t *testing.T, mem *InMemoryRateLimitStore, clock *time.Time) {
+				limit := RateLimit{Requests: 1, Window: time.Minute}
+				res, _ := mem.Allow("ip:1", limit)
+				assert.True(t, res.Allowed)
+				res, _ = mem.Allow("ip:2", limit)
+				assert.True(t, res.Allowed)
+			},
+		},
+		{
+			name: "idle buckets are cleaned up",
+			run: func(t *testing.T, mem *InMemoryRateLimitStore, clock *time.Time) {
+				limit := RateLimit{Requests: 1, Window: time.Second}
+				_, _ = mem.Allow("ip:1", limit)
+				*clock = clock.Add(2 * rateLimitCleanupInterval)
+				_, _ = mem.Allow("ip:2", limit)
+				assert.Len(t, mem.buckets, 1)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			mem := NewInMemoryRateLimitStore()
+			mem.now = func() time.Time { return clock }
+			mem.lastCleanup = clock
+			test.run(t, mem, &clock)
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	store := NewInMemoryRateLimitStore()
+	limit := RateLimit{Requests: 1, Window: time.Minute}
+	handler := RateLimitMiddleware(store, limit, KeyByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		wantStatus     int
+		wantRetryAfter bool
+	}{
+		{name: "first request passes", remoteAddr: "10.0.0.1:1234", wantStatus: http.StatusOK},
+		{name: "second request is limited", remoteAddr: "10.0.0.1:4321", wantStatus: http.StatusTooManyRequests, wantRetryAfter: true},
+		{name: "other ip passes", remoteAddr: "10.0.0.2:1234", wantStatus: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = test.remoteAddr
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+			assert.Equal(t, "1", resp.Header.Get("RateLimit-Limit"))
+			assert.NotEmpty(t, resp.Header.Get("RateLimit-Remaining"))
+			assert.NotEmpty(t, resp.Header.Get("RateLimit-Reset"))
+			if test.wantRetryAfter {
+				assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+			} else {
+				assert.Empty(t, resp.Header.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareRejectsEmptyLimits(t *testing.T) {
+	store := NewInMemoryRateLimitStore()
+	assert.Panics(t, func() { RateLimitMiddleware(store, RateLimit{Requests: 1}, KeyByIP) })
+	assert.Panics(t, func() { RateLimitMiddleware(store, RateLimit{Window: time.Minute}, KeyByIP) })
+}
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"172.16.0.0/12", "10.0.0.5"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		want       string
+	}{
+		{name: "trusted network", remoteAddr: "172.18.0.3:5000", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted address", remoteAddr: "10.0.0.5:5000", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "untrusted client", remoteAddr: "198.51.100.1:5000", realIP: "203.0.113.7", want: "198.51.100.1"},
+		{name: "trusted without header", remoteAddr: "10.0.0.5:5000", want: "10.0.0.5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.realIP != "" {
+				req.Header.Set("X-Real-IP", test.realIP)
+			}
+
+			var got string
+			RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"proxy"})
+	assert.EqualError(t, err, `invalid trusted proxy "proxy"`)
+}
+
+func TestRealIPWarnsWithoutTrustedProxies(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	handler := RealIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(realIP string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "172.18.0.2:5000"
+		if realIP != "" {
+			req.Header.Set("X-Real-IP", realIP)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("")
+	assert.Empty(t, logs.String(), "requests without the header aren't proxied")
+	serve("203.0.113.7")
+	serve("203.0.113.8")
+	assert.Equal(t, 1, strings.Count(logs.String(), "server.trusted_proxies is empty"), "warns once")
+	assert.Contains(t, logs.String(), "proxy=172.18.0.2")
+}
+
+func TestKeyByPrincipal(t *testing.T) {
+	sessionID, userID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      string
+	}{
+		{name: "user", principal: &auth.Principal{SessionID: sessionID, User: &user.User{Id: userID}}, want: "user:" + userID.String()},
+		{name: "anonymous session", principal: &auth.Principal{SessionID: sessionID}, want: "session:" + sessionID.String()},
+		{name: "no session", want: "ip:10.0.0.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			if test.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), test.principal))
+			}
+			assert.Equal(t, test.want, KeyByPrincipal(req))
+		})
+	}
+}
+
+func TestRateLimitMiddlewareKeysByRoute(t *testing.T) {
+	limit := RateLimit{Requests: 1, Window: time.Hour}
+	mux := http.NewServeMux()
+	mux.Handle("POST /articles/{id}/report", RateLimitMiddleware(NewInMemoryRateLimitStore(), limit, KeyByIP)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	codes := make([]int, 0, 2)
+	for _, id := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodPost, "/articles/"+id+"/report", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes, "another target must not get a fresh bucket")
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+// RecoverMiddleware turns a handler panic into a logged 500 instead of a dropped connection.
+func RecoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// the stdlib uses this panic to abort a response on purpose
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			logger.FromContext(r.Context()).Error("panic recovered",
+				"panic", fmt.Sprint(rec),
+				"stack", string(debug.Stack()),
+			)
+			json.WriteAppError(w, apperror.New(apperror.CodeInternal, "internal server error"))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/logger"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+// bufferedWriter holds the response back until it has been validated.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if bw.status == 0 {
+		bw.status = status
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	return bw.body.Write(b)
+}
+
+// Middleware rejects requests that don't match the document with a 400 and
+// replaces nonconforming responses with a 500. Buffering every response is
+// too costly for production; it is meant for test and staging deployments.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, json.MaxBodyBytes+1))
+		if err != nil {
+			json.WriteAppError(w, apperror.Wrap(apperror.CodeBadRequest, "invalid request body", err))
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := v.ValidateRequest(r, body); err != nil {
+			json.WriteAppError(w, apperror.Wrap(apperror.CodeValidation, err.Error(), err))
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: w}
+		next.ServeHTTP(bw, r)
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+
+		if err := v.ValidateResponse(r, bw.status, w.Header().Get("Content-Type"), bw.body.Bytes()); err != nil {
+			logger.FromContext(r.Context()).Error("response does not match openapi document", "error", err)
+			w.Header().Del("Content-Length")
+			json.WriteAppError(w, apperror.New(apperror.CodeInternal, "internal server error"))
+			return
+		}
+
+		w.WriteHeader(bw.status)
+		w.Write(bw.body.Bytes())
+	})
+}
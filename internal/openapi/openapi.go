@@ -0,0 +1,359 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+const specURL = "openapi.json"
+
+func Spec() []byte {
+	return spec
+}
+
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// The docs page renders the document with its own small script instead of
+// Swagger UI, so it loads nothing from third parties. Its
+// Content-Security-Policy allows only that script and style, pinned by hash.
+const docsStyle = `
+body { font: 15px/1.5 system-ui, sans-serif; margin: 2rem auto; max-width: 60rem; padding: 0 1rem; }
+summary { cursor: pointer; padding: .25rem 0; }
+code { font-weight: bold; }
+details { border-bottom: 1px solid #ddd; }
+details > :not(summary) { margin-left: 1.5rem; }
+`
+
+const docsScript = `
+const methods = ["get", "put", "post", "delete", "patch", "head", "options"];
+const root = document.getElementById("docs");
+
+function el(tag, text) {
+  const node = document.createElement(tag);
+  if (text !== undefined) node.textContent = text;
+  return node;
+}
+
+function list(title, items) {
+  const section = el("div");
+  section.append(el("h4", title));
+  const ul = el("ul");
+  for (const item of items) ul.append(el("li", item));
+  section.append(ul);
+  return section;
+}
+
+function refName(ref) {
+  return ref.split("/").pop();
+}
+
+function schemaNames(content) {
+  return Object.entries(content || {}).map(([type, media]) =>
+    type + (media.schema && media.schema.$ref ? ": " + refName(media.schema.$ref) : ""));
+}
+
+fetch("openapi.json").then((resp) => resp.json()).then((spec) => {
+  document.title = spec.info.title;
+  root.append(el("h1", spec.info.title + " " + spec.info.version));
+  if (spec.info.description) root.append(el("p", spec.info.description));
+  const link = el("a", "openapi.json");
+  link.href = "openapi.json";
+  const raw = el("p", "The document itself: ");
+  raw.append(link);
+  root.append(raw);
+
+  for (const [path, item] of Object.entries(spec.paths)) {
+    for (const method of methods) {
+      const op = item[method];
+      if (!op) continue;
+      const details = el("details");
+      const summary = el("summary");
+      summary.append(el("code", method.toUpperCase() + " " + path), " " + (op.summary || ""));
+      details.append(summary);
+      if (op.description) details.append(el("p", op.description));
+      if (op.security && op.security.length) {
+        details.append(el("p", "Requires: " + op.security.map((s) => Object.keys(s).join(" + ")).join(" or ")));
+      }
+      const params = (item.parameters || []).concat(op.parameters || []);
+      if (params.length) {
+        details.append(list("Parameters", params.map((p) => p.name + " (" + p.in + ")" + (p.description ? ": " + p.description : ""))));
+      }
+      if (op.requestBody) details.append(list("Request body", schemaNames(op.requestBody.content)));
+      details.append(list("Responses", Object.entries(op.responses).map(([status, resp]) => {
+        if (resp.$ref) resp = spec.components.responses[refName(resp.$ref)];
+        const names = schemaNames(resp.content);
+        return status + " " + (resp.description || "") + (names.length ? " (" + names.join(", ") + ")" : "");
+      })));
+      root.append(details);
+    }
+  }
+}).catch((err) => {
+  root.textContent = "Failed to load openapi.json: " + err;
+});
+`
+
+var (
+	docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>MindLeak API</title>
+  <style>` + docsStyle + `</style>
+</head>
+<body>
+  <main id="docs"></main>
+  <script>` + docsScript + `</script>
+</body>
+</html>
+`
+	docsPolicy = "default-src 'none'; connect-src 'self'; script-src " + sourceHash(docsScript) +
+		"; style-src " + sourceHash(docsStyle)
+)
+
+// sourceHash is the CSP source expression that allows exactly this inline
+// script or style.
+func sourceHash(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", docsPolicy)
+	w.Write([]byte(docsPage))
+}
+
+// httpMethods are the operation keys of a path item; its other fields,
+// such as shared parameters, don't affect validation.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type operationObject struct {
+	RequestBody *struct {
+		Required bool                       `json:"required"`
+		Content  map[string]json.RawMessage `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Ref     string                     `json:"$ref"`
+		Content map[string]json.RawMessage `json:"content"`
+	} `json:"responses"`
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Responses map[string]struct {
+			Content map[string]json.RawMessage `json:"content"`
+		} `json:"responses"`
+	} `json:"components"`
+}
+
+type operation struct {
+	method   string
+	path     string
+	segments []string
+	// request is nil when the operation takes no JSON body.
+	request         *jsonschema.Schema
+	requestRequired bool
+	// responses maps a status code to its JSON schema, or to nil for
+	// documented non-JSON responses.
+	responses map[int]*jsonschema.Schema
+}
+
+// Validator checks requests and responses against the embedded document.
+type Validator struct {
+	operations []operation
+}
+
+func NewValidator() (*Validator, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+
+	raw, err := jsonschema.UnmarshalJSON(bytes.NewReader(spec))
+	if err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource(specURL, raw); err != nil {
+		return nil, err
+	}
+	compile := func(pointer ...string) (*jsonschema.Schema, error) {
+		for i, token := range pointer {
+			pointer[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+		}
+		return compiler.Compile(specURL + "#/" + strings.Join(pointer, "/"))
+	}
+
+	v := &Validator{}
+	for path, item := range doc.Paths {
+		for _, method := range httpMethods {
+			rawOp, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operationObject
+			if err := json.Unmarshal(rawOp, &op); err != nil {
+				return nil, fmt.Errorf("parse %s %s: %w", method, path, err)
+			}
+
+			compiled := operation{
+				method:    strings.ToUpper(method),
+				path:      path,
+				segments:  strings.Split(path, "/"),
+				responses: make(map[int]*jsonschema.Schema),
+			}
+
+			if op.RequestBody != nil {
+				if _, ok := op.RequestBody.Content["application/json"]; ok {
+					compiled.requestRequired = op.RequestBody.Required
+					compiled.request, err = compile("paths", path, method, "requestBody", "content", "application/json", "schema")
+					if err != nil {
+						return nil, fmt.Errorf("%s %s request: %w", method, path, err)
+					}
+				}
+			}
+
+			for code, resp := range op.Responses {
+				status, err := strconv.Atoi(code)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: unsupported response key %q", method, path, code)
+				}
+
+				pointer := []string{"paths", path, method, "responses", code, "content", "application/json", "schema"}
+				content := resp.Content
+				if resp.Ref != "" {
+					name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+					content = doc.Components.Responses[name].Content
+					pointer = []string{"components", "responses", name, "content", "application/json", "schema"}
+				}
+				if _, ok := content["application/json"]; !ok {
+					compiled.responses[status] = nil
+					continue
+				}
+				compiled.responses[status], err = compile(pointer...)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s response %d: %w", method, path, status, err)
+				}
+			}
+
+			v.operations = append(v.operations, compiled)
+		}
+	}
+	return v, nil
+}
+
+// Operations lists every documented operation as "METHOD path", sorted.
+func (v *Validator) Operations() []string {
+	ops := make([]string, 0, len(v.operations))
+	for _, op := range v.operations {
+		ops = append(ops, op.method+" "+op.path)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// find matches a request path against path templates such as
+// /api/v1/articles/{id}. HEAD is documented implicitly by GET.
+func (v *Validator) find(method, path string) *operation {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	segments := strings.Split(path, "/")
+	for i := range v.operations {
+		op := &v.operations[i]
+		if op.method == method && matchSegments(op.segments, segments) {
+			return op
+		}
+	}
+	return nil
+}
+
+func matchSegments(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, t := range template {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if t != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateRequest checks a JSON request body. Requests to undocumented
+// operations and bodies that aren't JSON are left for the router and
+// handlers to reject.
+func (v *Validator) ValidateRequest(r *http.Request, body []byte) error {
+	op := v.find(r.Method, r.URL.Path)
+	if op == nil || op.request == nil || !isJSON(r.Header.Get("Content-Type")) {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.requestRequired {
+			return fmt.Errorf("%s %s: request body is required", op.method, op.path)
+		}
+		return nil
+	}
+	return validate(op.request, body)
+}
+
+// ValidateResponse fails on statuses the document doesn't list for the
+// operation and on JSON bodies that don't match the documented schema.
+func (v *Validator) ValidateResponse(r *http.Request, status int, contentType string, body []byte) error {
+	op := v.find(r.Method, r.URL.Path)
+	if op == nil {
+		return nil
+	}
+	schema, ok := op.responses[status]
+	if !ok {
+		return fmt.Errorf("%s %s: undocumented response status %d", op.method, op.path, status)
+	}
+	if schema == nil || r.Method == http.MethodHead {
+		return nil
+	}
+	if !isJSON(contentType) {
+		return fmt.Errorf("%s %s: response %d must be application/json, got %q", op.method, op.path, status, contentType)
+	}
+	if err := validate(schema, body); err != nil {
+		return fmt.Errorf("%s %s: response %d: %w", op.method, op.path, status, err)
+	}
+	return nil
+}
+
+func validate(schema *jsonschema.Schema, body []byte) error {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return schema.Validate(instance)
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(mediaType) == "application/json"
+}
@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponse(t *testing.T) {
+	v, err := NewValidator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{name: "matching user", method: http.MethodGet, path: "/api/v1/me", status: http.StatusOK, contentType: "application/json", body: `{"id":"f47ac10b-58cc-4372-a567-0e02b2c3d479","email":"a@b.ru","name":"A","avatar":"","roles":["user"]}`},
+		{name: "leaked field", method: http.MethodGet, path: "/api/v1/me", status: http.StatusOK, contentType: "application/json", body: `{"id":"f47ac10b-58cc-4372-a567-0e02b2c3d479","email":"a@b.ru","name":"A","avatar":"","roles":["user"],"password":"x"}`, wantErr: "additional properties"},
+		{name: "missing field", method: http.MethodGet, path: "/api/v1/me", status: http.StatusOK, contentType: "application/json", body: `{"email":"a@b.ru"}`, wantErr: "missing properties"},
+		{name: "error envelope", method: http.MethodGet, path: "/api/v1/me", status: http.StatusUnauthorized, contentType: "application/json", body: `{"error":"authentication required","code":"unauthorized"}`},
+		{name: "unknown error code", method: http.MethodGet, path: "/api/v1/me", status: http.StatusUnauthorized, contentType: "application/json", body: `{"error":"nope","code":"nope"}`, wantErr: "code"},
+		{name: "undocumented status", method: http.MethodGet, path: "/api/v1/me", status: http.StatusTeapot, contentType: "application/json", body: `{}`, wantErr: "undocumented response status 418"},
+		{name: "wrong content type", method: http.MethodGet, path: "/api/v1/me", status: http.StatusOK, contentType: "text/plain", body: `{}`, wantErr: "must be application/json"},
+		{name: "path template", method: http.MethodGet, path: "/api/v1/articles/f47ac10b-58cc-4372-a567-0e02b2c3d479", status: http.StatusNotFound, contentType: "application/json", body: `{"error":"article not found","code":"not_found"}`},
+		{name: "head skips body", method: http.MethodHead, path: "/healthz", status: http.StatusOK},
+		{name: "non-json response", method: http.MethodGet, path: "/metrics", status: http.StatusOK, contentType: "text/plain", body: "up 1"},
+		{name: "undocumented operation", method: http.MethodDelete, path: "/api/v1/me", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			err := v.ValidateResponse(req, tt.status, tt.contentType, []byte(tt.body))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	v, err := NewValidator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{name: "valid", contentType: "application/json", body: `{"email":"a@b.ru","password":"password1"}`},
+		{name: "unknown field", contentType: "application/json", body: `{"email":"a@b.ru","password":"password1","admin":true}`, wantErr: true},
+		{name: "wrong type", contentType: "application/json", body: `{"email":1,"password":"password1"}`, wantErr: true},
+		{name: "missing body", contentType: "application/json", wantErr: true},
+		{name: "not json is left to the handler", contentType: "text/plain", body: "email=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/login", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			err := v.ValidateRequest(req, []byte(tt.body))
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v, err := NewValidator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		response   string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "conforming", body: `{"email":"a@b.ru","password":"password1"}`, response: `{"id":"f47ac10b-58cc-4372-a567-0e02b2c3d479","email":"a@b.ru","name":"A","avatar":"","roles":["user"]}`, wantStatus: http.StatusOK},
+		{name: "invalid request", body: `{"login":"a"}`, wantStatus: http.StatusBadRequest, wantBody: `"code":"validation_failed"`},
+		{name: "invalid response", body: `{"email":"a@b.ru","password":"password1"}`, response: `{"email":"a@b.ru"}`, wantStatus: http.StatusInternalServerError, wantBody: `"code":"internal_error"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tt.response))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			} else {
+				assert.Equal(t, tt.response, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDocsHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	DocsHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	page := w.Body.String()
+	assert.NotContains(t, page, "https://", "the page must not load third-party assets")
+
+	policy := w.Header().Get("Content-Security-Policy")
+	assert.Contains(t, policy, "script-src "+sourceHash(between(t, page, "<script>", "</script>")))
+	assert.Contains(t, policy, "style-src "+sourceHash(between(t, page, "<style>", "</style>")))
+}
+
+func between(t *testing.T, s, open, close string) string {
+	_, rest, ok := strings.Cut(s, open)
+	require.True(t, ok, open)
+	inner, _, ok := strings.Cut(rest, close)
+	require.True(t, ok, close)
+	return inner
+}
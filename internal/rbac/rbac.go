@@ -0,0 +1,124 @@
+package rbac
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAuthor    Role = "author"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	ArticleCreate    Permission = "article.create"
+	ArticleUpdateOwn Permission = "article.update.own"
+	ArticleDeleteOwn Permission = "article.delete.own"
+	ArticlePublish   Permission = "article.publish"
+	ArticleHideAny   Permission = "article.hide.any"
+	ArticleDeleteAny Permission = "article.delete.any"
+	CommentCreate    Permission = "comment.create"
+	CommentDeleteOwn Permission = "comment.delete.own"
+	CommentDeleteAny Permission = "comment.delete.any"
+	ReportCreate     Permission = "report.create"
+	ReportReview     Permission = "report.review"
+	UserWarn         Permission = "user.warn"
+	UserBan          Permission = "user.ban"
+	RoleManage       Permission = "role.manage"
+)
+
+// rolePermissions lists what each role adds on top of the roles below it;
+// a user holding several roles gets the union.
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {
+		ArticleCreate, ArticleUpdateOwn, ArticleDeleteOwn,
+		CommentCreate, CommentDeleteOwn,
+		ReportCreate,
+	},
+	RoleAuthor: {
+		ArticlePublish,
+	},
+	RoleModerator: {
+		ArticleHideAny, ArticleDeleteAny,
+		CommentDeleteAny,
+		ReportReview,
+		UserWarn, UserBan,
+	},
+	RoleAdmin: {
+		RoleManage,
+	},
+}
+
+// inherits says which roles' permissions a role includes.
+var inherits = map[Role][]Role{
+	RoleUser:      {},
+	RoleAuthor:    {RoleUser},
+	RoleModerator: {RoleAuthor},
+	RoleAdmin:     {RoleModerator},
+}
+
+func Roles() []Role {
+	return []Role{RoleUser, RoleAuthor, RoleModerator, RoleAdmin}
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Can reports whether any of the roles grants the permission.
+func Can(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		if roleCan(role, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func roleCan(role Role, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	for _, parent := range inherits[role] {
+		if roleCan(parent, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the sorted union of permissions of the roles.
+func Permissions(roles []Role) []Permission {
+	seen := make(map[Permission]bool)
+	var collect func(Role)
+	collect = func(role Role) {
+		for _, p := range rolePermissions[role] {
+			seen[p] = true
+		}
+		for _, parent := range inherits[role] {
+			collect(parent)
+		}
+	}
+	for _, role := range roles {
+		collect(role)
+	}
+
+	permissions := make([]Permission, 0, len(seen))
+	for p := range seen {
+		permissions = append(permissions, p)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+	return permissions
+}
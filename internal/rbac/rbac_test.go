@@ -0,0 +1,52 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []Role
+		permission Permission
+		want       bool
+	}{
+		{name: "user creates articles", roles: []Role{RoleUser}, permission: ArticleCreate, want: true},
+		{name: "user cannot publish", roles: []Role{RoleUser}, permission: ArticlePublish, want: false},
+		{name: "author publishes", roles: []Role{RoleUser, RoleAuthor}, permission: ArticlePublish, want: true},
+		{name: "author cannot delete others' articles", roles: []Role{RoleAuthor}, permission: ArticleDeleteAny, want: false},
+		{name: "moderator bans", roles: []Role{RoleModerator}, permission: UserBan, want: true},
+		{name: "moderator inherits author", roles: []Role{RoleModerator}, permission: ArticlePublish, want: true},
+		{name: "moderator cannot manage roles", roles: []Role{RoleModerator}, permission: RoleManage, want: false},
+		{name: "admin can do everything", roles: []Role{RoleAdmin}, permission: ArticleDeleteAny, want: true},
+		{name: "no roles", roles: nil, permission: ArticleCreate, want: false},
+		{name: "unknown role", roles: []Role{"root"}, permission: ArticleCreate, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Can(tt.roles, tt.permission))
+		})
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	admin := Permissions([]Role{RoleAdmin})
+	for _, role := range Roles() {
+		for _, p := range Permissions([]Role{role}) {
+			assert.Contains(t, admin, p, "admin must hold every permission")
+		}
+	}
+	assert.Equal(t, Permissions([]Role{RoleModerator}), Permissions([]Role{RoleUser, RoleModerator}), "duplicates are merged")
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := ParseRole("moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, RoleModerator, role)
+
+	_, err = ParseRole("root")
+	assert.EqualError(t, err, `unknown role "root"`)
+}
@@ -2,29 +2,79 @@ package article
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
 	"github.com/google/uuid"
 )
 
 type ArticleRepository interface {
-	CreateArticle(authorId uuid.UUID, title, content string) (*Article, error)
+	CreateArticle(author Author, title string, format Format, content string, record Record) (*Article, error)
 	GetArticleById(id uuid.UUID) (*Article, error)
 	GetArticlesByAuthorId(authorId uuid.UUID) ([]*Article, error)
 	GetAllArticles() ([]*Article, error)
 	DeleteArticle(id uuid.UUID) (bool, error)
+	HideArticle(id uuid.UUID) (*Article, error)
+	UpdateArticle(id uuid.UUID, title string, format Format, content string, record Record) (*Article, error)
+	SetStatus(id uuid.UUID, status Status, publishAt *time.Time) (*Article, error)
+	SetVisibility(id uuid.UUID, visibility Visibility) (*Article, error)
+}
+
+// Record is called by CreateArticle and UpdateArticle with the version about
+// to be stored, under the same lock, so whatever it writes (the revision
+// history) can't get out of step with the article. If it fails nothing is
+// stored. It may be nil.
+type Record func(saved *Article) error
+
+var (
+	ErrArticleNotFound = errors.New("article not found")
+	ErrDuplicateTitle  = errors.New("article with this title already exists for this author")
+)
+
+// Author is the article's author as of its creation; the name and avatar
+// are copied so listings don't look up every author.
+type Author struct {
+	Id     uuid.UUID
+	Name   string
+	Avatar string
 }
 
 type Article struct {
-	Id           uuid.UUID `json:"-"`
-	AuthorId     uuid.UUID `json:"-"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"-"`
-	Image        string    `json:"image"`
-	AuthorName   string    `json:"author_name"`
-	AuthorAvatar string    `json:"author_avatar"`
+	Id       uuid.UUID `json:"id"`
+	AuthorId uuid.UUID `json:"-"`
+	Title    string    `json:"title"`
+	// Slug is transliterated from Title and unique among all articles;
+	// PreviousSlugs are the ones replaced by title changes, which still
+	// redirect to the article.
+	Slug          string   `json:"slug"`
+	PreviousSlugs []string `json:"-"`
+	Format        Format   `json:"format"`
+	Content       string   `json:"content"`
+	// ContentHTML, PlainText, Excerpt and the counts are derived from
+	// Content on every save, so reads never parse it.
+	ContentHTML string `json:"content_html"`
+	// PlainText is the readable text, one block per line, for search.
+	PlainText string `json:"-"`
+	Excerpt   string `json:"excerpt"`
+	WordCount int    `json:"word_count"`
+	// ReadingMinutes is rounded up; code is not counted.
+	ReadingMinutes int        `json:"reading_minutes"`
+	CreatedAt      time.Time  `json:"-"`
+	Image          string     `json:"image"`
+	AuthorName     string     `json:"author_name"`
+	AuthorAvatar   string     `json:"author_avatar"`
+	Status         Status     `json:"status"`
+	Visibility     Visibility `json:"visibility"`
+	// PublishAt is set only while the article is scheduled.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// PublishedAt is the first publication; republishing an archived
+	// article keeps it.
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// Hidden articles were taken down by a moderator; they stay stored so
+	// the decision can be reviewed.
+	Hidden bool `json:"-"`
 }
 
 type InMemoryArticle struct {
@@ -36,56 +86,82 @@ func NewInMemoryArticle() *InMemoryArticle {
 	articles := &InMemoryArticle{
 		Articles: make([]Article, 0),
 	}
-	authorID := uuid.New()
+	author := Author{
+		Id:     uuid.New(),
+		Name:   "Алексей Владимиров",
+		Avatar: "https://sun9-88.userapi.com/s/v1/ig2/P_e5HW2lWX3ZxayBg73NnzbHzyhxFCXtBseRjSrN_NbemNC78OpkeYfJeXcTOXqyR8NhSwizZKqJEq_R8PhQo607.jpg?quality=95&as=32x40,48x60,72x90,108x135,160x200,240x300,360x450,480x600,540x675,640x800,720x900,1080x1350,1280x1600,1440x1800,1620x2025&from=bu&cs=1620x0",
+	}
 
-	_, _ = articles.CreateArticle(authorID,
-		"ИИ в 2025: Как нейросети меняют бизнес-процессы",
-		"Искусственный интеллект в 2025 году стал неотъемлемой частью бизнеса...")
+	_, _ = articles.createArticle(author,
+		"ИИ в 2025: Как нейросети меняют бизнес-процессы", FormatMarkdown,
+		"Искусственный интеллект в 2025 году стал неотъемлемой частью бизнеса...", StatusPublished, nil)
 
-	_, _ = articles.CreateArticle(authorID,
-		"Как российский стартап привлёк $10M на рынке SaaS",
-		"Российский стартап CloudPeak разработал SaaS-платформу...")
+	_, _ = articles.createArticle(author,
+		"Как российский стартап привлёк $10M на рынке SaaS", FormatMarkdown,
+		"Российский стартап CloudPeak разработал SaaS-платформу...", StatusPublished, nil)
 
-	_, _ = articles.CreateArticle(authorID,
-		"Тренды контент-маркетинга: Что работает в 2025 году",
-		"Контент-маркетинг в 2025 году переживает новый виток...")
+	_, _ = articles.createArticle(author,
+		"Тренды контент-маркетинга: Что работает в 2025 году", FormatMarkdown,
+		"Контент-маркетинг в 2025 году переживает новый виток...", StatusPublished, nil)
 
-	_, _ = articles.CreateArticle(authorID,
-		"Почему 80% стартапов терпят неудачу в первый год",
-		"Запуск стартапа — это всегда риск...")
+	_, _ = articles.createArticle(author,
+		"Почему 80% стартапов терпят неудачу в первый год", FormatMarkdown,
+		"Запуск стартапа — это всегда риск...", StatusPublished, nil)
 
-	_, _ = articles.CreateArticle(authorID,
-		"Как мы увеличили конверсию на 30% с помощью UX",
-		"Компания BrightPath переработала интерфейс...")
+	_, _ = articles.createArticle(author,
+		"Как мы увеличили конверсию на 30% с помощью UX", FormatMarkdown,
+		"Компания BrightPath переработала интерфейс...", StatusPublished, nil)
 
-	_, _ = articles.CreateArticle(authorID,
-		"Экспериментальный сверхдлинный заголовок статьи, в котором мы попробуем уместить сразу и суть, и интригу, и даже немного юмора, чтобы проверить, как фронтенд справится с рендерингом текста...",
-		`Это тестовое содержимое статьи, которое специально сделано очень длинным, чтобы проверить работу фронтенда с большими объёмами текста... (длинный текст)`)
+	_, _ = articles.createArticle(author,
+		"Экспериментальный сверхдлинный заголовок статьи, в котором мы попробуем уместить сразу и суть, и интригу, и даже немного юмора, чтобы проверить, как фронтенд справится с рендерингом текста...", FormatMarkdown,
+		`Это тестовое содержимое статьи, которое специально сделано очень длинным, чтобы проверить работу фронтенда с большими объёмами текста... (длинный текст)`, StatusPublished, nil)
 
 	return articles
 }
 
-func (mem *InMemoryArticle) CreateArticle(authorID uuid.UUID, title, content string) (*Article, error) {
+// CreateArticle stores a public draft; publishing is a separate step.
+func (mem *InMemoryArticle) CreateArticle(author Author, title string, format Format, content string, record Record) (*Article, error) {
+	article, err := mem.createArticle(author, title, format, content, StatusDraft, record)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ArticlesCreated.Inc()
+	return article, nil
+}
+
+// createArticle is used directly for the seed articles so they don't count as created.
+func (mem *InMemoryArticle) createArticle(author Author, title string, format Format, content string, status Status, record Record) (*Article, error) {
 	mem.mu.Lock()
 	defer mem.mu.Unlock()
 
 	for _, article := range mem.Articles {
-		if article.Title == title && article.AuthorId == authorID {
+		if article.Title == title && article.AuthorId == author.Id {
 
-			return nil, errors.New("article with this title already exists for this author")
+			return nil, ErrDuplicateTitle
 		}
 	}
 
 	article := Article{
 		Id:           uuid.New(),
-		AuthorId:     authorID,
+		AuthorId:     author.Id,
 		Title:        title,
-		Content:      content,
 		CreatedAt:    time.Now(),
-		AuthorName:   "Алексей Владимиров",
-		AuthorAvatar: "https://sun9-88.userapi.com/s/v1/ig2/P_e5HW2lWX3ZxayBg73NnzbHzyhxFCXtBseRjSrN_NbemNC78OpkeYfJeXcTOXqyR8NhSwizZKqJEq_R8PhQo607.jpg?quality=95&as=32x40,48x60,72x90,108x135,160x200,240x300,360x450,480x600,540x675,640x800,720x900,1080x1350,1280x1600,1440x1800,1620x2025&from=bu&cs=1620x0",
+		AuthorName:   author.Name,
+		AuthorAvatar: author.Avatar,
+		Status:       StatusDraft,
+		Visibility:   VisibilityPublic,
 		Image:        "https://st4.depositphotos.com/36740986/38337/i/450/depositphotos_383375990-stock-photo-collection-hundred-dollar-banknotes-female.jpg",
 	}
+	if err := article.setContent(format, content); err != nil {
+		return nil, err
+	}
+	article.setSlug(mem.uniqueSlug(title, article.Id))
+	if status == StatusPublished {
+		article.publish(article.CreatedAt)
+	}
+	if err := runRecord(record, article); err != nil {
+		return nil, err
+	}
 	mem.Articles = append(mem.Articles, article)
 	copyArticle := article
 	return &copyArticle, nil
@@ -102,7 +178,7 @@ func (mem *InMemoryArticle) GetArticleById(articleID uuid.UUID) (*Article, error
 		}
 	}
 
-	return nil, errors.New("article not found")
+	return nil, ErrArticleNotFound
 }
 
 func (mem *InMemoryArticle) GetArticlesByAuthorId(authorId uuid.UUID) ([]*Article, error) {
@@ -145,5 +221,113 @@ func (mem *InMemoryArticle) DeleteArticle(articleID uuid.UUID) (bool, error) {
 		}
 	}
 
-	return false, errors.New("article not found")
+	return false, ErrArticleNotFound
+}
+
+func (mem *InMemoryArticle) HideArticle(articleID uuid.UUID) (*Article, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Articles {
+		if mem.Articles[i].Id == articleID {
+			mem.Articles[i].Hidden = true
+			copyArticle := mem.Articles[i]
+			return &copyArticle, nil
+		}
+	}
+
+	return nil, ErrArticleNotFound
+}
+
+func (mem *InMemoryArticle) UpdateArticle(articleID uuid.UUID, title string, format Format, content string, record Record) (*Article, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	idx := -1
+	for i := range mem.Articles {
+		if mem.Articles[i].Id == articleID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, ErrArticleNotFound
+	}
+	for i := range mem.Articles {
+		if i != idx && mem.Articles[i].AuthorId == mem.Articles[idx].AuthorId && mem.Articles[i].Title == title {
+			return nil, ErrDuplicateTitle
+		}
+	}
+
+	updated := mem.Articles[idx]
+	if err := updated.setContent(format, content); err != nil {
+		return nil, err
+	}
+	if updated.Title != title {
+		updated.setSlug(mem.uniqueSlug(title, articleID))
+	}
+	updated.Title = title
+	if err := runRecord(record, updated); err != nil {
+		return nil, err
+	}
+	mem.Articles[idx] = updated
+	copyArticle := updated
+	return &copyArticle, nil
+}
+
+// runRecord hands record a copy so it can't change what gets stored.
+func runRecord(record Record, article Article) error {
+	if record == nil {
+		return nil
+	}
+	return record(&article)
+}
+
+// SetStatus moves the article along the lifecycle. Scheduling needs a
+// publishAt in the future; other statuses ignore it.
+func (mem *InMemoryArticle) SetStatus(articleID uuid.UUID, status Status, publishAt *time.Time) (*Article, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Articles {
+		a := &mem.Articles[i]
+		if a.Id != articleID {
+			continue
+		}
+		if !a.Status.CanMoveTo(status) {
+			return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, a.Status, status)
+		}
+		now := time.Now()
+		switch status {
+		case StatusScheduled:
+			if publishAt == nil || !publishAt.After(now) {
+				return nil, ErrPublishAtNotInFuture
+			}
+			at := *publishAt
+			a.Status, a.PublishAt = status, &at
+		case StatusPublished:
+			a.publish(now)
+		default:
+			a.Status, a.PublishAt = status, nil
+		}
+		copyArticle := *a
+		return &copyArticle, nil
+	}
+
+	return nil, ErrArticleNotFound
+}
+
+func (mem *InMemoryArticle) SetVisibility(articleID uuid.UUID, visibility Visibility) (*Article, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Articles {
+		if mem.Articles[i].Id == articleID {
+			mem.Articles[i].Visibility = visibility
+			copyArticle := mem.Articles[i]
+			return &copyArticle, nil
+		}
+	}
+
+	return nil, ErrArticleNotFound
 }
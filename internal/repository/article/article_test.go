@@ -1,9 +1,14 @@
 package article
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,7 +21,7 @@ func TestArticle(t *testing.T) {
 			name: "CreateArticle creates new article",
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID := uuid.New()
-				a, err := mem.CreateArticle(authorID, "Test Title", "Test Content")
+				a, err := mem.CreateArticle(Author{Id: authorID, Name: "Мария", Avatar: "https://example.com/maria.png"}, "Test Title", FormatMarkdown, "Test Content", nil)
 				assert.NoError(t, err)
 				assert.NotNil(t, a)
 				assert.NotEqual(t, uuid.Nil, a.Id)
@@ -24,20 +29,31 @@ func TestArticle(t *testing.T) {
 				assert.Equal(t, "Test Title", a.Title)
 				assert.Equal(t, "Test Content", a.Content)
 				assert.NotEmpty(t, a.CreatedAt)
+				assert.Equal(t, StatusDraft, a.Status)
+				assert.Equal(t, VisibilityPublic, a.Visibility)
+				assert.Nil(t, a.PublishedAt)
 				assert.Equal(t, "https://st4.depositphotos.com/36740986/38337/i/450/depositphotos_383375990-stock-photo-collection-hundred-dollar-banknotes-female.jpg", a.Image)
-				assert.Equal(t, "Алексей Владимиров", a.AuthorName)
-				assert.Equal(t, "https://sun9-88.userapi.com/s/v1/ig2/P_e5HW2lWX3ZxayBg73NnzbHzyhxFCXtBseRjSrN_NbemNC78OpkeYfJeXcTOXqyR8NhSwizZKqJEq_R8PhQo607.jpg?quality=95&as=32x40,48x60,72x90,108x135,160x200,240x300,360x450,480x600,540x675,640x800,720x900,1080x1350,1280x1600,1440x1800,1620x2025&from=bu&cs=1620x0", a.AuthorAvatar)
+				assert.Equal(t, "Мария", a.AuthorName, "the author comes from the caller, not the seeds")
+				assert.Equal(t, "https://example.com/maria.png", a.AuthorAvatar)
 
 				assert.Len(t, mem.Articles, 7)
 				assert.Equal(t, a.Id, mem.Articles[6].Id)
 			},
 		},
+		{
+			name: "CreateArticle counts created articles",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				before := testutil.ToFloat64(metrics.ArticlesCreated)
+				_, _ = mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				assert.Equal(t, before+1, testutil.ToFloat64(metrics.ArticlesCreated))
+			},
+		},
 		{
 			name: "CreateArticle returns error if title already exists for author",
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID := uuid.New()
-				_, _ = mem.CreateArticle(authorID, "Test Title", "Test Content")
-				_, err := mem.CreateArticle(authorID, "Test Title", "New Content")
+				_, _ = mem.CreateArticle(Author{Id: authorID}, "Test Title", FormatMarkdown, "Test Content", nil)
+				_, err := mem.CreateArticle(Author{Id: authorID}, "Test Title", FormatMarkdown, "New Content", nil)
 				assert.EqualError(t, err, "article with this title already exists for this author")
 			},
 		},
@@ -45,7 +61,7 @@ func TestArticle(t *testing.T) {
 			name: "GetArticleById returns existing article",
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID := uuid.New()
-				a, _ := mem.CreateArticle(authorID, "Test Title", "Test Content")
+				a, _ := mem.CreateArticle(Author{Id: authorID}, "Test Title", FormatMarkdown, "Test Content", nil)
 				got, err := mem.GetArticleById(a.Id)
 				assert.NoError(t, err)
 				assert.Equal(t, a.Id, got.Id)
@@ -70,9 +86,9 @@ func TestArticle(t *testing.T) {
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID1 := uuid.New()
 				authorID2 := uuid.New()
-				_, _ = mem.CreateArticle(authorID1, "Title1", "Content1")
-				_, _ = mem.CreateArticle(authorID1, "Title2", "Content2")
-				_, _ = mem.CreateArticle(authorID2, "Title3", "Content3")
+				_, _ = mem.CreateArticle(Author{Id: authorID1}, "Title1", FormatMarkdown, "Content1", nil)
+				_, _ = mem.CreateArticle(Author{Id: authorID1}, "Title2", FormatMarkdown, "Content2", nil)
+				_, _ = mem.CreateArticle(Author{Id: authorID2}, "Title3", FormatMarkdown, "Content3", nil)
 				result, err := mem.GetArticlesByAuthorId(authorID1)
 				assert.NoError(t, err)
 				assert.Len(t, result, 2)
@@ -92,8 +108,8 @@ func TestArticle(t *testing.T) {
 			name: "GetAllArticles returns all articles",
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID := uuid.New()
-				_, _ = mem.CreateArticle(authorID, "Title1", "Content1")
-				_, _ = mem.CreateArticle(authorID, "Title2", "Content2")
+				_, _ = mem.CreateArticle(Author{Id: authorID}, "Title1", FormatMarkdown, "Content1", nil)
+				_, _ = mem.CreateArticle(Author{Id: authorID}, "Title2", FormatMarkdown, "Content2", nil)
 				all, err := mem.GetAllArticles()
 				assert.NoError(t, err)
 				assert.Len(t, all, 2+6) // 6 mock articles from NewInMemoryArticle + 2 new
@@ -103,7 +119,7 @@ func TestArticle(t *testing.T) {
 			name: "DeleteArticle deletes existing article",
 			run: func(t *testing.T, mem *InMemoryArticle) {
 				authorID := uuid.New()
-				a, _ := mem.CreateArticle(authorID, "Test Title", "Test Content")
+				a, _ := mem.CreateArticle(Author{Id: authorID}, "Test Title", FormatMarkdown, "Test Content", nil)
 				ok, err := mem.DeleteArticle(a.Id)
 				assert.True(t, ok)
 				assert.NoError(t, err)
@@ -118,6 +134,279 @@ func TestArticle(t *testing.T) {
 				assert.EqualError(t, err, "article not found")
 			},
 		},
+		{
+			name: "HideArticle hides the article but keeps it",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				hidden, err := mem.HideArticle(a.Id)
+				assert.NoError(t, err)
+				assert.True(t, hidden.Hidden)
+				got, err := mem.GetArticleById(a.Id)
+				assert.NoError(t, err)
+				assert.True(t, got.Hidden)
+			},
+		},
+		{
+			name: "HideArticle returns error if not found",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				_, err := mem.HideArticle(uuid.New())
+				assert.EqualError(t, err, "article not found")
+			},
+		},
+		{
+			name: "UpdateArticle changes title and content",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				updated, err := mem.UpdateArticle(a.Id, "New Title", FormatMarkdown, "New Content", nil)
+				assert.NoError(t, err)
+				assert.Equal(t, "New Title", updated.Title)
+				assert.Equal(t, "New Content", updated.Content)
+				got, _ := mem.GetArticleById(a.Id)
+				assert.Equal(t, "New Title", got.Title)
+			},
+		},
+		{
+			name: "saving renders content to HTML and an excerpt",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "**Test** Content", nil)
+				assert.Equal(t, "<p><strong>Test</strong> Content</p>\n", a.ContentHTML)
+				assert.Equal(t, "Test Content", a.Excerpt)
+				updated, _ := mem.UpdateArticle(a.Id, "Test Title", FormatMarkdown, "New <script>alert(1)</script> ||spoiler||", nil)
+				assert.NotContains(t, updated.ContentHTML, "<script")
+				assert.Contains(t, updated.ContentHTML, `<span class="spoiler">spoiler</span>`)
+				assert.Equal(t, "New alert(1) …", updated.Excerpt)
+			},
+		},
+		{
+			name: "saving counts words and cuts the excerpt at a sentence",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				content := strings.Repeat("Первое предложение статьи. ", 20) + "Конец."
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, content, nil)
+				assert.Equal(t, strings.TrimSpace(strings.Repeat("Первое предложение статьи. ", 11)), a.Excerpt)
+				assert.Equal(t, 61, a.WordCount)
+				assert.Equal(t, 1, a.ReadingMinutes)
+
+				updated, _ := mem.UpdateArticle(a.Id, "Test Title", FormatMarkdown, strings.Repeat("слово ", 400)+"\n\n```go\n"+strings.Repeat("code ", 1000)+"\n```", nil)
+				assert.Equal(t, 400, updated.WordCount)
+				assert.Equal(t, 3, updated.ReadingMinutes)
+			},
+		},
+		{
+			name: "block documents are validated and stored compact",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, err := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatBlocks, `{
+					"version": 1,
+					"blocks": [{"type": "header", "data": {"text": "Test", "level": 2}}, {"type": "paragraph", "data": {"text": "<b>Test</b> Content"}}]
+				}`, nil)
+
+				assert.NoError(t, err)
+				assert.Equal(t, FormatBlocks, a.Format)
+				assert.Equal(t, `{"version":1,"blocks":[{"type":"header","data":{"text":"Test","level":2}},{"type":"paragraph","data":{"text":"<b>Test</b> Content"}}]}`, a.Content)
+				assert.Equal(t, "<h2>Test</h2>\n<p><b>Test</b> Content</p>\n", a.ContentHTML)
+				assert.Equal(t, "Test\nTest Content", a.PlainText)
+
+				_, err = mem.UpdateArticle(a.Id, "Test Title", FormatBlocks, `{"version":1,"blocks":[{"type":"video","data":{}}]}`, nil)
+				assert.ErrorIs(t, err, ErrInvalidContent)
+				got, _ := mem.GetArticleById(a.Id)
+				assert.Equal(t, a.Content, got.Content)
+			},
+		},
+		{
+			name: "UpdateArticle keeps titles unique per author",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				authorID := uuid.New()
+				a, _ := mem.CreateArticle(Author{Id: authorID}, "Title1", FormatMarkdown, "Content", nil)
+				_, _ = mem.CreateArticle(Author{Id: authorID}, "Title2", FormatMarkdown, "Content", nil)
+				_, err := mem.UpdateArticle(a.Id, "Title2", FormatMarkdown, "Content", nil)
+				assert.ErrorIs(t, err, ErrDuplicateTitle)
+				_, err = mem.UpdateArticle(a.Id, "Title1", FormatMarkdown, "Other content", nil)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "slugs are transliterated and unique",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				assert.Equal(t, "ii-v-2025-kak-neyroseti-menyayut-biznes-protsessy", mem.Articles[0].Slug)
+
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Новая статья", FormatMarkdown, "Content", nil)
+				b, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Новая статья!", FormatMarkdown, "Content", nil)
+				c, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Новая статья", FormatMarkdown, "Content", nil)
+				assert.Equal(t, "novaya-statya", a.Slug)
+				assert.Equal(t, "novaya-statya-2", b.Slug)
+				assert.Equal(t, "novaya-statya-3", c.Slug)
+			},
+		},
+		{
+			name: "UpdateArticle keeps old slugs for redirects",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Первый заголовок", FormatMarkdown, "Content", nil)
+
+				updated, _ := mem.UpdateArticle(a.Id, "Первый заголовок", FormatMarkdown, "Other content", nil)
+				assert.Equal(t, "pervyy-zagolovok", updated.Slug)
+				assert.Empty(t, updated.PreviousSlugs)
+
+				updated, _ = mem.UpdateArticle(a.Id, "Второй заголовок", FormatMarkdown, "Content", nil)
+				assert.Equal(t, "vtoroy-zagolovok", updated.Slug)
+				assert.Equal(t, []string{"pervyy-zagolovok"}, updated.PreviousSlugs)
+				assert.True(t, updated.HasSlug("pervyy-zagolovok"))
+				assert.False(t, updated.HasSlug("tretiy-zagolovok"))
+
+				// an old slug stays taken by its article
+				other, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Первый заголовок", FormatMarkdown, "Content", nil)
+				assert.Equal(t, "pervyy-zagolovok-2", other.Slug)
+
+				// going back to the old title brings its slug back
+				updated, _ = mem.UpdateArticle(a.Id, "Первый заголовок", FormatMarkdown, "Content", nil)
+				assert.Equal(t, "pervyy-zagolovok", updated.Slug)
+				assert.Equal(t, []string{"vtoroy-zagolovok"}, updated.PreviousSlugs)
+			},
+		},
+		{
+			name: "UpdateArticle returns error if not found",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				_, err := mem.UpdateArticle(uuid.New(), "Title", FormatMarkdown, "Content", nil)
+				assert.ErrorIs(t, err, ErrArticleNotFound)
+			},
+		},
+		{
+			name: "a failing record stores nothing",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				failing := func(*Article) error { return errors.New("history unavailable") }
+				count := len(mem.Articles)
+				_, err := mem.CreateArticle(Author{Id: uuid.New()}, "Title", FormatMarkdown, "Content", failing)
+				assert.EqualError(t, err, "history unavailable")
+				assert.Len(t, mem.Articles, count)
+
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Title", FormatMarkdown, "Content", nil)
+				_, err = mem.UpdateArticle(a.Id, "Other title", FormatMarkdown, "Other content", failing)
+				assert.EqualError(t, err, "history unavailable")
+				stored, _ := mem.GetArticleById(a.Id)
+				assert.Equal(t, a, stored)
+			},
+		},
+		{
+			name: "record sees the version being stored",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				var recorded *Article
+				record := func(saved *Article) error {
+					recorded = saved
+					return nil
+				}
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Title", FormatMarkdown, "Content", record)
+				assert.Equal(t, a, recorded)
+				updated, _ := mem.UpdateArticle(a.Id, "Title", FormatMarkdown, "**Other** content", record)
+				assert.Equal(t, updated, recorded)
+				assert.Equal(t, "Other content", recorded.PlainText)
+			},
+		},
+		{
+			name: "seed articles are published",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				for _, a := range mem.Articles {
+					assert.Equal(t, StatusPublished, a.Status)
+					assert.NotNil(t, a.PublishedAt)
+					assert.NotEmpty(t, a.ContentHTML)
+				}
+			},
+		},
+		{
+			name: "SetStatus publishes a draft",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				published, err := mem.SetStatus(a.Id, StatusPublished, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, StatusPublished, published.Status)
+				assert.NotNil(t, published.PublishedAt)
+			},
+		},
+		{
+			name: "SetStatus schedules a draft",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				at := time.Now().Add(time.Hour)
+				scheduled, err := mem.SetStatus(a.Id, StatusScheduled, &at)
+				assert.NoError(t, err)
+				assert.Equal(t, StatusScheduled, scheduled.Status)
+				assert.Equal(t, at, *scheduled.PublishAt)
+				assert.Nil(t, scheduled.PublishedAt)
+
+				draft, err := mem.SetStatus(a.Id, StatusDraft, nil)
+				assert.NoError(t, err)
+				assert.Nil(t, draft.PublishAt)
+			},
+		},
+		{
+			name: "SetStatus rejects scheduling in the past",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				past := time.Now().Add(-time.Minute)
+				_, err := mem.SetStatus(a.Id, StatusScheduled, &past)
+				assert.ErrorIs(t, err, ErrPublishAtNotInFuture)
+				_, err = mem.SetStatus(a.Id, StatusScheduled, nil)
+				assert.ErrorIs(t, err, ErrPublishAtNotInFuture)
+			},
+		},
+		{
+			name: "SetStatus rejects invalid transitions",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				_, err := mem.SetStatus(a.Id, StatusArchived, nil)
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+				assert.EqualError(t, err, "invalid status transition from draft to archived")
+			},
+		},
+		{
+			name: "SetStatus keeps the first publication date when republishing",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				published, _ := mem.SetStatus(a.Id, StatusPublished, nil)
+				archived, err := mem.SetStatus(a.Id, StatusArchived, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, StatusArchived, archived.Status)
+				republished, err := mem.SetStatus(a.Id, StatusPublished, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, published.PublishedAt, republished.PublishedAt)
+			},
+		},
+		{
+			name: "SetStatus returns error if not found",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				_, err := mem.SetStatus(uuid.New(), StatusPublished, nil)
+				assert.ErrorIs(t, err, ErrArticleNotFound)
+			},
+		},
+		{
+			name: "PublishDue publishes only due articles",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				soon, later := time.Now().Add(time.Minute), time.Now().Add(time.Hour)
+				due, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Due", FormatMarkdown, "Content", nil)
+				_, _ = mem.SetStatus(due.Id, StatusScheduled, &soon)
+				notDue, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Not due", FormatMarkdown, "Content", nil)
+				_, _ = mem.SetStatus(notDue.Id, StatusScheduled, &later)
+
+				published, err := mem.PublishDue(soon)
+				assert.NoError(t, err)
+				if assert.Len(t, published, 1) {
+					assert.Equal(t, due.Id, published[0].Id)
+					assert.Equal(t, StatusPublished, published[0].Status)
+					assert.Nil(t, published[0].PublishAt)
+					assert.Equal(t, soon, *published[0].PublishedAt)
+				}
+				got, _ := mem.GetArticleById(notDue.Id)
+				assert.Equal(t, StatusScheduled, got.Status)
+			},
+		},
+		{
+			name: "SetVisibility changes the visibility",
+			run: func(t *testing.T, mem *InMemoryArticle) {
+				a, _ := mem.CreateArticle(Author{Id: uuid.New()}, "Test Title", FormatMarkdown, "Test Content", nil)
+				updated, err := mem.SetVisibility(a.Id, VisibilityPrivate)
+				assert.NoError(t, err)
+				assert.Equal(t, VisibilityPrivate, updated.Visibility)
+				_, err = mem.SetVisibility(uuid.New(), VisibilityPrivate)
+				assert.ErrorIs(t, err, ErrArticleNotFound)
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,60 @@
+package article
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/blocks"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/markdown"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/textstats"
+)
+
+// Format says how Content is written.
+type Format string
+
+const (
+	// FormatMarkdown content is Markdown; plain text is Markdown too.
+	FormatMarkdown Format = "markdown"
+	// FormatBlocks content is a block document from the editor, see
+	// package blocks.
+	FormatBlocks Format = "blocks"
+)
+
+var ErrInvalidContent = errors.New("invalid content")
+
+// excerptLength is how many characters of an article a feed card shows at
+// most; the excerpt ends at a sentence boundary.
+const excerptLength = 300
+
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatMarkdown, FormatBlocks:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q", s)
+}
+
+// setContent stores the content with everything derived from it. Block
+// documents are validated and stored in their compact form.
+func (a *Article) setContent(format Format, content string) error {
+	switch format {
+	case FormatBlocks:
+		doc, err := blocks.Parse([]byte(content))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidContent, err)
+		}
+		a.Content = doc.Marshal()
+		a.ContentHTML = doc.HTML()
+		a.PlainText = doc.PlainText()
+	default:
+		format = FormatMarkdown
+		a.Content = content
+		a.ContentHTML = markdown.Render(content)
+		a.PlainText = markdown.PlainText(content)
+	}
+	a.Format = format
+	a.Excerpt = textstats.Excerpt(a.PlainText, excerptLength)
+	stats := textstats.Count(a.PlainText)
+	a.WordCount, a.ReadingMinutes = stats.Words, stats.ReadingMinutes
+	return nil
+}
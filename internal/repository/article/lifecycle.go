@@ -0,0 +1,110 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusDraft Status = "draft"
+	// StatusScheduled articles are published by the scheduler at PublishAt.
+	StatusScheduled Status = "scheduled"
+	StatusPublished Status = "published"
+	// StatusArchived articles leave the feed but stay readable by link.
+	StatusArchived Status = "archived"
+)
+
+var (
+	ErrInvalidTransition    = errors.New("invalid status transition")
+	ErrPublishAtNotInFuture = errors.New("publish_at must be in the future")
+)
+
+// transitions lists the statuses an article may move to from each status.
+var transitions = map[Status][]Status{
+	StatusDraft:     {StatusScheduled, StatusPublished},
+	StatusScheduled: {StatusDraft, StatusPublished},
+	StatusPublished: {StatusArchived},
+	StatusArchived:  {StatusPublished},
+}
+
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if _, ok := transitions[status]; !ok {
+		return "", fmt.Errorf("unknown status %q", s)
+	}
+	return status, nil
+}
+
+func (s Status) CanMoveTo(next Status) bool {
+	return slices.Contains(transitions[s], next)
+}
+
+type Visibility string
+
+const (
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted articles are readable by link but never listed.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityFollowers articles are listed and readable only for the
+	// author's followers.
+	VisibilityFollowers Visibility = "followers"
+	VisibilityPrivate   Visibility = "private"
+)
+
+func Visibilities() []Visibility {
+	return []Visibility{VisibilityPublic, VisibilityUnlisted, VisibilityFollowers, VisibilityPrivate}
+}
+
+func ParseVisibility(s string) (Visibility, error) {
+	for _, v := range Visibilities() {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unknown visibility %q", s)
+}
+
+// PublishDue publishes the scheduled articles whose time has come and
+// returns them.
+func (mem *InMemoryArticle) PublishDue(now time.Time) ([]*Article, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	published := make([]*Article, 0)
+	for i := range mem.Articles {
+		a := &mem.Articles[i]
+		if a.Status == StatusScheduled && a.PublishAt != nil && !a.PublishAt.After(now) {
+			a.publish(now)
+			copyArticle := *a
+			published = append(published, &copyArticle)
+		}
+	}
+	return published, nil
+}
+
+func (mem *InMemoryArticle) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _ = mem.PublishDue(now)
+		}
+	}
+}
+
+func (a *Article) publish(now time.Time) {
+	a.Status = StatusPublished
+	a.PublishAt = nil
+	if a.PublishedAt == nil {
+		a.PublishedAt = &now
+	}
+}
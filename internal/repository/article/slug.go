@@ -0,0 +1,52 @@
+package article
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/slug"
+	"github.com/google/uuid"
+)
+
+// uniqueSlug returns the slug of the title, suffixed with "-2", "-3"... when
+// another article uses it now or used it before. The article's own old
+// slugs are free, so going back to an old title restores its slug.
+// The caller holds mem.mu.
+func (mem *InMemoryArticle) uniqueSlug(title string, self uuid.UUID) string {
+	taken := make(map[string]bool)
+	for i := range mem.Articles {
+		if mem.Articles[i].Id == self {
+			continue
+		}
+		taken[mem.Articles[i].Slug] = true
+		for _, old := range mem.Articles[i].PreviousSlugs {
+			taken[old] = true
+		}
+	}
+
+	base := slug.Make(title)
+	candidate := base
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	return candidate
+}
+
+// setSlug makes s the current slug and keeps the old one for redirects.
+func (a *Article) setSlug(s string) {
+	if a.Slug == s {
+		return
+	}
+	previous := slices.DeleteFunc(slices.Clone(a.PreviousSlugs), func(old string) bool { return old == s })
+	if a.Slug != "" {
+		previous = append(previous, a.Slug)
+	}
+	a.PreviousSlugs = previous
+	a.Slug = s
+}
+
+// HasSlug reports whether s is the current slug or one the article had
+// before a title change.
+func (a *Article) HasSlug(s string) bool {
+	return s == a.Slug || slices.Contains(a.PreviousSlugs, s)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is append-only: moderation and admin decisions are never edited
+// or deleted once recorded.
+type AuditLog interface {
+	Record(entry Entry) (*Entry, error)
+	GetEntries(limit int) ([]*Entry, error)
+}
+
+// Actions recorded by the moderation and admin handlers.
+const (
+	ActionRoleGrant     = "role.grant"
+	ActionRoleRevoke    = "role.revoke"
+	ActionReportClaim   = "report.claim"
+	ActionReportDismiss = "report.dismiss"
+	ActionArticleHide   = "article.hide"
+	ActionArticleDelete = "article.delete"
+	ActionUserWarn      = "user.warn"
+	ActionUserBan       = "user.ban"
+	ActionUserSuspend   = "user.suspend"
+	ActionUserShadowBan = "user.shadowban"
+	ActionUserLift      = "user.lift"
+)
+
+type Entry struct {
+	Id         uuid.UUID  `json:"id"`
+	ActorId    uuid.UUID  `json:"actor_id"`
+	Action     string     `json:"action"`
+	TargetType string     `json:"target_type"`
+	TargetId   uuid.UUID  `json:"target_id"`
+	ReportId   *uuid.UUID `json:"report_id,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type InMemoryAuditLog struct {
+	Entries []Entry
+	mu      sync.RWMutex
+}
+
+func NewInMemoryAuditLog() *InMemoryAuditLog {
+	return &InMemoryAuditLog{
+		Entries: make([]Entry, 0),
+	}
+}
+
+// Record assigns the id and timestamp itself so callers can't backdate entries.
+func (mem *InMemoryAuditLog) Record(entry Entry) (*Entry, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	entry.Id = uuid.New()
+	entry.CreatedAt = time.Now()
+	mem.Entries = append(mem.Entries, entry)
+	copyEntry := entry
+	return &copyEntry, nil
+}
+
+// GetEntries returns up to limit entries, newest first.
+func (mem *InMemoryAuditLog) GetEntries(limit int) ([]*Entry, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	result := make([]*Entry, 0, min(limit, len(mem.Entries)))
+	for i := len(mem.Entries) - 1; i >= 0 && len(result) < limit; i-- {
+		temp := mem.Entries[i]
+		result = append(result, &temp)
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, mem *InMemoryAuditLog)
+	}{
+		{
+			name: "Record assigns id and time",
+			run: func(t *testing.T, mem *InMemoryAuditLog) {
+				actorID := uuid.New()
+				e, err := mem.Record(Entry{ActorId: actorID, Action: "article.hide", TargetType: "article", TargetId: uuid.New()})
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, e.Id)
+				assert.False(t, e.CreatedAt.IsZero())
+				assert.Equal(t, actorID, e.ActorId)
+			},
+		},
+		{
+			name: "GetEntries returns newest first up to the limit",
+			run: func(t *testing.T, mem *InMemoryAuditLog) {
+				for _, action := range []string{"first", "second", "third"} {
+					_, _ = mem.Record(Entry{Action: action})
+				}
+				got, err := mem.GetEntries(2)
+				assert.NoError(t, err)
+				require.Len(t, got, 2)
+				assert.Equal(t, "third", got[0].Action)
+				assert.Equal(t, "second", got[1].Action)
+			},
+		},
+		{
+			name: "GetEntries returns empty slice if nothing recorded",
+			run: func(t *testing.T, mem *InMemoryAuditLog) {
+				got, err := mem.GetEntries(10)
+				assert.NoError(t, err)
+				assert.Empty(t, got)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mem := NewInMemoryAuditLog()
+			test.run(t, mem)
+		})
+	}
+}
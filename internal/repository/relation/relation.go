@@ -0,0 +1,184 @@
+package relation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MaxMutedKeywords bounds the per-article matching work in the feed.
+const MaxMutedKeywords = 100
+
+var ErrMuteLimit = errors.New("too many muted keywords")
+
+// RelationRepository stores how users filter each other: a block hides the
+// two users' content from each other; mutes only trim the muting user's feed;
+// following an author opens their followers-only articles.
+type RelationRepository interface {
+	Block(userId, targetId uuid.UUID) error
+	Unblock(userId, targetId uuid.UUID) error
+	MuteUser(userId, targetId uuid.UUID) error
+	UnmuteUser(userId, targetId uuid.UUID) error
+	MuteKeyword(userId uuid.UUID, keyword string) error
+	UnmuteKeyword(userId uuid.UUID, keyword string) error
+	Follow(userId, targetId uuid.UUID) error
+	Unfollow(userId, targetId uuid.UUID) error
+	GetRelations(userId uuid.UUID) (*Relations, error)
+}
+
+// Relations is everything a feed needs about one viewer, fetched in one call.
+type Relations struct {
+	Blocked       []uuid.UUID
+	BlockedBy     []uuid.UUID
+	MutedUsers    []uuid.UUID
+	MutedKeywords []string
+	Following     []uuid.UUID
+}
+
+type edge struct {
+	from uuid.UUID
+	to   uuid.UUID
+}
+
+type InMemoryRelation struct {
+	blocks   map[edge]struct{}
+	mutes    map[edge]struct{}
+	keywords map[uuid.UUID][]string
+	follows  map[edge]struct{}
+	mu       sync.RWMutex
+}
+
+func NewInMemoryRelation() *InMemoryRelation {
+	return &InMemoryRelation{
+		blocks:   make(map[edge]struct{}),
+		mutes:    make(map[edge]struct{}),
+		keywords: make(map[uuid.UUID][]string),
+		follows:  make(map[edge]struct{}),
+	}
+}
+
+// NormalizeKeyword is how keywords are stored and matched: trimmed and lower-cased.
+func NormalizeKeyword(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
+
+func (mem *InMemoryRelation) Block(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	mem.blocks[edge{userId, targetId}] = struct{}{}
+	return nil
+}
+
+func (mem *InMemoryRelation) Unblock(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	delete(mem.blocks, edge{userId, targetId})
+	return nil
+}
+
+func (mem *InMemoryRelation) MuteUser(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	mem.mutes[edge{userId, targetId}] = struct{}{}
+	return nil
+}
+
+func (mem *InMemoryRelation) UnmuteUser(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	delete(mem.mutes, edge{userId, targetId})
+	return nil
+}
+
+func (mem *InMemoryRelation) MuteKeyword(userId uuid.UUID, keyword string) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	keyword = NormalizeKeyword(keyword)
+	if slices.Contains(mem.keywords[userId], keyword) {
+		return nil
+	}
+	if len(mem.keywords[userId]) >= MaxMutedKeywords {
+		return ErrMuteLimit
+	}
+	mem.keywords[userId] = append(mem.keywords[userId], keyword)
+	return nil
+}
+
+func (mem *InMemoryRelation) UnmuteKeyword(userId uuid.UUID, keyword string) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	keyword = NormalizeKeyword(keyword)
+	mem.keywords[userId] = slices.DeleteFunc(mem.keywords[userId], func(k string) bool { return k == keyword })
+	if len(mem.keywords[userId]) == 0 {
+		delete(mem.keywords, userId)
+	}
+	return nil
+}
+
+func (mem *InMemoryRelation) Follow(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	mem.follows[edge{userId, targetId}] = struct{}{}
+	return nil
+}
+
+func (mem *InMemoryRelation) Unfollow(userId, targetId uuid.UUID) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	delete(mem.follows, edge{userId, targetId})
+	return nil
+}
+
+// GetRelations returns sorted copies so responses are stable.
+func (mem *InMemoryRelation) GetRelations(userId uuid.UUID) (*Relations, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	relations := &Relations{
+		Blocked:       make([]uuid.UUID, 0),
+		BlockedBy:     make([]uuid.UUID, 0),
+		MutedUsers:    make([]uuid.UUID, 0),
+		MutedKeywords: slices.Clone(mem.keywords[userId]),
+		Following:     make([]uuid.UUID, 0),
+	}
+	for e := range mem.blocks {
+		if e.from == userId {
+			relations.Blocked = append(relations.Blocked, e.to)
+		}
+		if e.to == userId {
+			relations.BlockedBy = append(relations.BlockedBy, e.from)
+		}
+	}
+	for e := range mem.mutes {
+		if e.from == userId {
+			relations.MutedUsers = append(relations.MutedUsers, e.to)
+		}
+	}
+	for e := range mem.follows {
+		if e.from == userId {
+			relations.Following = append(relations.Following, e.to)
+		}
+	}
+
+	compare := func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) }
+	slices.SortFunc(relations.Blocked, compare)
+	slices.SortFunc(relations.BlockedBy, compare)
+	slices.SortFunc(relations.MutedUsers, compare)
+	slices.SortFunc(relations.Following, compare)
+	if relations.MutedKeywords == nil {
+		relations.MutedKeywords = make([]string, 0)
+	}
+	slices.Sort(relations.MutedKeywords)
+	return relations, nil
+}
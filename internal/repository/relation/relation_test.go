@@ -0,0 +1,113 @@
+package relation
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelation(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, mem *InMemoryRelation)
+	}{
+		{
+			name: "Block is one-directional",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a, b := uuid.New(), uuid.New()
+				assert.NoError(t, mem.Block(a, b))
+
+				rel, _ := mem.GetRelations(a)
+				assert.Equal(t, []uuid.UUID{b}, rel.Blocked)
+				assert.Empty(t, rel.BlockedBy)
+				rel, _ = mem.GetRelations(b)
+				assert.Equal(t, []uuid.UUID{a}, rel.BlockedBy)
+			},
+		},
+		{
+			name: "Unblock removes the block",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a, b := uuid.New(), uuid.New()
+				_ = mem.Block(a, b)
+				assert.NoError(t, mem.Unblock(a, b))
+				rel, _ := mem.GetRelations(a)
+				assert.Empty(t, rel.Blocked)
+				rel, _ = mem.GetRelations(b)
+				assert.Empty(t, rel.BlockedBy)
+			},
+		},
+		{
+			name: "MuteUser and UnmuteUser",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a, b := uuid.New(), uuid.New()
+				assert.NoError(t, mem.MuteUser(a, b))
+				rel, _ := mem.GetRelations(a)
+				assert.Equal(t, []uuid.UUID{b}, rel.MutedUsers)
+
+				assert.NoError(t, mem.UnmuteUser(a, b))
+				rel, _ = mem.GetRelations(a)
+				assert.Empty(t, rel.MutedUsers)
+			},
+		},
+		{
+			name: "Follow and Unfollow",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a, b := uuid.New(), uuid.New()
+				assert.NoError(t, mem.Follow(a, b))
+				assert.NoError(t, mem.Follow(a, b))
+				rel, _ := mem.GetRelations(a)
+				assert.Equal(t, []uuid.UUID{b}, rel.Following)
+				rel, _ = mem.GetRelations(b)
+				assert.Empty(t, rel.Following, "following is one-directional")
+
+				assert.NoError(t, mem.Unfollow(a, b))
+				rel, _ = mem.GetRelations(a)
+				assert.Empty(t, rel.Following)
+			},
+		},
+		{
+			name: "MuteKeyword normalizes and deduplicates",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a := uuid.New()
+				assert.NoError(t, mem.MuteKeyword(a, "  Крипта "))
+				assert.NoError(t, mem.MuteKeyword(a, "КРИПТА"))
+				assert.NoError(t, mem.MuteKeyword(a, "nft"))
+				rel, _ := mem.GetRelations(a)
+				assert.Equal(t, []string{"nft", "крипта"}, rel.MutedKeywords)
+
+				assert.NoError(t, mem.UnmuteKeyword(a, "Крипта"))
+				rel, _ = mem.GetRelations(a)
+				assert.Equal(t, []string{"nft"}, rel.MutedKeywords)
+			},
+		},
+		{
+			name: "MuteKeyword enforces the limit",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				a := uuid.New()
+				for i := range MaxMutedKeywords {
+					assert.NoError(t, mem.MuteKeyword(a, uuid.NewString()[:8]+string(rune('a'+i%26))))
+				}
+				assert.ErrorIs(t, mem.MuteKeyword(a, "one more"), ErrMuteLimit)
+			},
+		},
+		{
+			name: "GetRelations of a user without relations is empty",
+			run: func(t *testing.T, mem *InMemoryRelation) {
+				rel, err := mem.GetRelations(uuid.New())
+				assert.NoError(t, err)
+				assert.Empty(t, rel.Blocked)
+				assert.Empty(t, rel.BlockedBy)
+				assert.Empty(t, rel.MutedUsers)
+				assert.NotNil(t, rel.MutedKeywords)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mem := NewInMemoryRelation()
+			test.run(t, mem)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package report
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportRepository interface {
+	CreateReport(targetType TargetType, targetId, reporterId uuid.UUID, reason Reason, details string) (*Report, error)
+	GetReportById(id uuid.UUID) (*Report, error)
+	GetReportsByStatus(status Status) ([]*Report, error)
+	ClaimReport(id, moderatorId uuid.UUID) (*Report, error)
+	ResolveReport(id, moderatorId uuid.UUID, action Action, note string, apply Apply) ([]*Report, error)
+}
+
+// TargetType is what a report is about. Comments don't exist yet, so there
+// is no comment target; adding one means a TargetComment here, a
+// POST /comments/{id}/report route and resolve actions that hide or delete
+// the comment.
+type TargetType string
+
+const (
+	TargetArticle TargetType = "article"
+	TargetUser    TargetType = "user"
+)
+
+type Reason string
+
+const (
+	ReasonSpam           Reason = "spam"
+	ReasonAbuse          Reason = "abuse"
+	ReasonHarassment     Reason = "harassment"
+	ReasonMisinformation Reason = "misinformation"
+	ReasonIllegal        Reason = "illegal"
+	ReasonOther          Reason = "other"
+)
+
+func (r Reason) Valid() bool {
+	switch r {
+	case ReasonSpam, ReasonAbuse, ReasonHarassment, ReasonMisinformation, ReasonIllegal, ReasonOther:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	StatusOpen     Status = "open"
+	StatusClaimed  Status = "claimed"
+	StatusResolved Status = "resolved"
+)
+
+func (s Status) Valid() bool {
+	return s == StatusOpen || s == StatusClaimed || s == StatusResolved
+}
+
+// Action is the moderator's decision. Hide and delete apply to reported
+// articles; warn and ban apply to the reported user or the article's author.
+type Action string
+
+const (
+	ActionDismiss Action = "dismiss"
+	ActionHide    Action = "hide"
+	ActionDelete  Action = "delete"
+	ActionWarn    Action = "warn"
+	ActionBan     Action = "ban"
+)
+
+// Apply carries out the decision. ResolveReport calls it under the
+// repository lock once the report is known to be resolvable by the
+// moderator, so two moderators can't both act on one report; if it fails
+// the report stays open. It may be nil.
+type Apply func(pending *Report) error
+
+var (
+	ErrReportNotFound  = errors.New("report not found")
+	ErrDuplicateReport = errors.New("you have already reported this")
+	ErrAlreadyClaimed  = errors.New("report is claimed by another moderator")
+	ErrAlreadyResolved = errors.New("report is already resolved")
+)
+
+type Report struct {
+	Id         uuid.UUID  `json:"id"`
+	TargetType TargetType `json:"target_type"`
+	TargetId   uuid.UUID  `json:"target_id"`
+	ReporterId uuid.UUID  `json:"reporter_id"`
+	Reason     Reason     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     Status     `json:"status"`
+	AssigneeId *uuid.UUID `json:"assignee_id,omitempty"`
+	Action     Action     `json:"action,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type InMemoryReport struct {
+	Reports []Report
+	mu      sync.RWMutex
+}
+
+func NewInMemoryReport() *InMemoryReport {
+	return &InMemoryReport{
+		Reports: make([]Report, 0),
+	}
+}
+
+// CreateReport rejects a second unresolved report of the same target by the
+// same reporter, so one user can't push something up the queue alone.
+func (mem *InMemoryReport) CreateReport(targetType TargetType, targetId, reporterId uuid.UUID, reason Reason, details string) (*Report, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for _, r := range mem.Reports {
+		if r.TargetType == targetType && r.TargetId == targetId && r.ReporterId == reporterId && r.Status != StatusResolved {
+			return nil, ErrDuplicateReport
+		}
+	}
+
+	report := Report{
+		Id:         uuid.New(),
+		TargetType: targetType,
+		TargetId:   targetId,
+		ReporterId: reporterId,
+		Reason:     reason,
+		Details:    details,
+		Status:     StatusOpen,
+		CreatedAt:  time.Now(),
+	}
+	mem.Reports = append(mem.Reports, report)
+	return report.copy(), nil
+}
+
+func (mem *InMemoryReport) GetReportById(id uuid.UUID) (*Report, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	for i := range mem.Reports {
+		if mem.Reports[i].Id == id {
+			return mem.Reports[i].copy(), nil
+		}
+	}
+	return nil, ErrReportNotFound
+}
+
+// GetReportsByStatus returns the oldest reports first, the order moderators work the queue in.
+func (mem *InMemoryReport) GetReportsByStatus(status Status) ([]*Report, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	result := make([]*Report, 0)
+	for i := range mem.Reports {
+		if mem.Reports[i].Status == status {
+			result = append(result, mem.Reports[i].copy())
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (mem *InMemoryReport) ClaimReport(id, moderatorId uuid.UUID) (*Report, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	r, err := mem.find(id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r.Status == StatusResolved:
+		return nil, ErrAlreadyResolved
+	case r.Status == StatusClaimed && *r.AssigneeId != moderatorId:
+		return nil, ErrAlreadyClaimed
+	}
+
+	r.Status = StatusClaimed
+	r.AssigneeId = &moderatorId
+	return r.copy(), nil
+}
+
+// ResolveReport closes the report and every other open report of the same
+// target with the same decision. The resolved reports are returned, the
+// requested one first.
+func (mem *InMemoryReport) ResolveReport(id, moderatorId uuid.UUID, action Action, note string, apply Apply) ([]*Report, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	r, err := mem.find(id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r.Status == StatusResolved:
+		return nil, ErrAlreadyResolved
+	case r.Status == StatusClaimed && *r.AssigneeId != moderatorId:
+		return nil, ErrAlreadyClaimed
+	}
+	if apply != nil {
+		if err := apply(r.copy()); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	resolve := func(r *Report) {
+		r.Status = StatusResolved
+		r.AssigneeId = &moderatorId
+		r.Action = action
+		r.Note = note
+		r.ResolvedAt = &now
+	}
+
+	resolve(r)
+	resolved := []*Report{r.copy()}
+	for i := range mem.Reports {
+		other := &mem.Reports[i]
+		if other.Id != id && other.TargetType == r.TargetType && other.TargetId == r.TargetId && other.Status == StatusOpen {
+			resolve(other)
+			resolved = append(resolved, other.copy())
+		}
+	}
+	return resolved, nil
+}
+
+func (mem *InMemoryReport) find(id uuid.UUID) (*Report, error) {
+	for i := range mem.Reports {
+		if mem.Reports[i].Id == id {
+			return &mem.Reports[i], nil
+		}
+	}
+	return nil, ErrReportNotFound
+}
+
+func (r Report) copy() *Report {
+	if r.AssigneeId != nil {
+		assignee := *r.AssigneeId
+		r.AssigneeId = &assignee
+	}
+	if r.ResolvedAt != nil {
+		resolvedAt := *r.ResolvedAt
+		r.ResolvedAt = &resolvedAt
+	}
+	return &r
+}
@@ -0,0 +1,176 @@
+package report
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, mem *InMemoryReport)
+	}{
+		{
+			name: "CreateReport creates an open report",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				targetID, reporterID := uuid.New(), uuid.New()
+				r, err := mem.CreateReport(TargetArticle, targetID, reporterID, ReasonSpam, "ads")
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, r.Id)
+				assert.Equal(t, TargetArticle, r.TargetType)
+				assert.Equal(t, targetID, r.TargetId)
+				assert.Equal(t, reporterID, r.ReporterId)
+				assert.Equal(t, StatusOpen, r.Status)
+				assert.Nil(t, r.AssigneeId)
+				assert.False(t, r.CreatedAt.IsZero())
+			},
+		},
+		{
+			name: "CreateReport rejects a duplicate by the same reporter",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				targetID, reporterID := uuid.New(), uuid.New()
+				_, _ = mem.CreateReport(TargetArticle, targetID, reporterID, ReasonSpam, "")
+				_, err := mem.CreateReport(TargetArticle, targetID, reporterID, ReasonAbuse, "")
+				assert.ErrorIs(t, err, ErrDuplicateReport)
+
+				_, err = mem.CreateReport(TargetArticle, targetID, uuid.New(), ReasonAbuse, "")
+				assert.NoError(t, err, "other reporters may report the same target")
+			},
+		},
+		{
+			name: "CreateReport allows reporting again after resolution",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				targetID, reporterID := uuid.New(), uuid.New()
+				r, _ := mem.CreateReport(TargetUser, targetID, reporterID, ReasonSpam, "")
+				_, err := mem.ResolveReport(r.Id, uuid.New(), ActionDismiss, "", nil)
+				require.NoError(t, err)
+				_, err = mem.CreateReport(TargetUser, targetID, reporterID, ReasonSpam, "")
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "GetReportById returns error if not found",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				_, err := mem.GetReportById(uuid.New())
+				assert.ErrorIs(t, err, ErrReportNotFound)
+			},
+		},
+		{
+			name: "GetReportsByStatus filters by status",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				open, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				claimed, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				_, _ = mem.ClaimReport(claimed.Id, uuid.New())
+
+				got, err := mem.GetReportsByStatus(StatusOpen)
+				assert.NoError(t, err)
+				require.Len(t, got, 1)
+				assert.Equal(t, open.Id, got[0].Id)
+
+				got, _ = mem.GetReportsByStatus(StatusClaimed)
+				require.Len(t, got, 1)
+				assert.Equal(t, claimed.Id, got[0].Id)
+			},
+		},
+		{
+			name: "ClaimReport assigns the moderator once",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				moderatorID := uuid.New()
+				r, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				claimed, err := mem.ClaimReport(r.Id, moderatorID)
+				assert.NoError(t, err)
+				assert.Equal(t, StatusClaimed, claimed.Status)
+				assert.Equal(t, moderatorID, *claimed.AssigneeId)
+
+				_, err = mem.ClaimReport(r.Id, moderatorID)
+				assert.NoError(t, err, "claiming again is idempotent")
+				_, err = mem.ClaimReport(r.Id, uuid.New())
+				assert.ErrorIs(t, err, ErrAlreadyClaimed)
+			},
+		},
+		{
+			name: "ResolveReport closes open reports of the same target",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				moderatorID, targetID := uuid.New(), uuid.New()
+				first, _ := mem.CreateReport(TargetArticle, targetID, uuid.New(), ReasonSpam, "")
+				second, _ := mem.CreateReport(TargetArticle, targetID, uuid.New(), ReasonAbuse, "")
+				other, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonAbuse, "")
+
+				resolved, err := mem.ResolveReport(first.Id, moderatorID, ActionHide, "spam wave", nil)
+				assert.NoError(t, err)
+				require.Len(t, resolved, 2)
+				assert.Equal(t, first.Id, resolved[0].Id)
+				assert.Equal(t, second.Id, resolved[1].Id)
+				for _, r := range resolved {
+					assert.Equal(t, StatusResolved, r.Status)
+					assert.Equal(t, ActionHide, r.Action)
+					assert.Equal(t, "spam wave", r.Note)
+					assert.NotNil(t, r.ResolvedAt)
+				}
+
+				got, _ := mem.GetReportById(other.Id)
+				assert.Equal(t, StatusOpen, got.Status)
+
+				_, err = mem.ResolveReport(first.Id, moderatorID, ActionDismiss, "", nil)
+				assert.ErrorIs(t, err, ErrAlreadyResolved)
+			},
+		},
+		{
+			name: "ResolveReport applies the decision once",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				r, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				var applied atomic.Int32
+				apply := func(pending *Report) error {
+					assert.Equal(t, r.Id, pending.Id)
+					applied.Add(1)
+					return nil
+				}
+
+				var wg sync.WaitGroup
+				for range 10 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, _ = mem.ResolveReport(r.Id, uuid.New(), ActionHide, "", apply)
+					}()
+				}
+				wg.Wait()
+				assert.Equal(t, int32(1), applied.Load())
+			},
+		},
+		{
+			name: "ResolveReport keeps the report open if the decision fails",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				r, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				_, err := mem.ResolveReport(r.Id, uuid.New(), ActionHide, "", func(*Report) error {
+					return errors.New("article not found")
+				})
+				assert.EqualError(t, err, "article not found")
+				got, _ := mem.GetReportById(r.Id)
+				assert.Equal(t, StatusOpen, got.Status)
+			},
+		},
+		{
+			name: "ResolveReport refuses a report claimed by someone else",
+			run: func(t *testing.T, mem *InMemoryReport) {
+				r, _ := mem.CreateReport(TargetArticle, uuid.New(), uuid.New(), ReasonSpam, "")
+				_, _ = mem.ClaimReport(r.Id, uuid.New())
+				_, err := mem.ResolveReport(r.Id, uuid.New(), ActionDismiss, "", nil)
+				assert.ErrorIs(t, err, ErrAlreadyClaimed)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mem := NewInMemoryReport()
+			test.run(t, mem)
+		})
+	}
+}
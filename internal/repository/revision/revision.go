@@ -0,0 +1,88 @@
+package revision
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/google/uuid"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// RevisionRepository keeps a snapshot of every saved version of an article.
+// Revisions are never deleted, not even with the article, so moderators can
+// review what a removed article used to say.
+type RevisionRepository interface {
+	AddRevision(rev Revision) (*Revision, error)
+	GetRevisions(articleId uuid.UUID) ([]*Revision, error)
+	GetRevision(articleId uuid.UUID, number int) (*Revision, error)
+}
+
+type Revision struct {
+	Id        uuid.UUID `json:"id"`
+	ArticleId uuid.UUID `json:"article_id"`
+	// Number counts the revisions of one article from 1.
+	Number   int            `json:"number"`
+	EditorId uuid.UUID      `json:"editor_id"`
+	Title    string         `json:"title"`
+	Format   article.Format `json:"format"`
+	Content  string         `json:"content"`
+	// PlainText is the readable text of the content, kept so diffs compare
+	// words rather than block JSON.
+	PlainText string `json:"-"`
+	// RestoredFrom is the number of the revision this one brought back.
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InMemoryRevision struct {
+	Revisions map[uuid.UUID][]Revision
+	mu        sync.RWMutex
+}
+
+func NewInMemoryRevision() *InMemoryRevision {
+	return &InMemoryRevision{
+		Revisions: make(map[uuid.UUID][]Revision),
+	}
+}
+
+// AddRevision assigns the id, number and timestamp itself.
+func (mem *InMemoryRevision) AddRevision(rev Revision) (*Revision, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	rev.Id = uuid.New()
+	rev.Number = len(mem.Revisions[rev.ArticleId]) + 1
+	rev.CreatedAt = time.Now()
+	mem.Revisions[rev.ArticleId] = append(mem.Revisions[rev.ArticleId], rev)
+	copyRevision := rev
+	return &copyRevision, nil
+}
+
+// GetRevisions returns the revisions of the article, oldest first.
+func (mem *InMemoryRevision) GetRevisions(articleId uuid.UUID) ([]*Revision, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	stored := mem.Revisions[articleId]
+	result := make([]*Revision, len(stored))
+	for i := range stored {
+		temp := stored[i]
+		result[i] = &temp
+	}
+	return result, nil
+}
+
+func (mem *InMemoryRevision) GetRevision(articleId uuid.UUID, number int) (*Revision, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	stored := mem.Revisions[articleId]
+	if number < 1 || number > len(stored) {
+		return nil, ErrRevisionNotFound
+	}
+	copyRevision := stored[number-1]
+	return &copyRevision, nil
+}
@@ -0,0 +1,80 @@
+package revision
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, mem *InMemoryRevision)
+	}{
+		{
+			name: "AddRevision numbers revisions per article",
+			run: func(t *testing.T, mem *InMemoryRevision) {
+				articleID, otherID := uuid.New(), uuid.New()
+				first, err := mem.AddRevision(Revision{ArticleId: articleID, Title: "Первая"})
+				assert.NoError(t, err)
+				second, _ := mem.AddRevision(Revision{ArticleId: articleID, Title: "Вторая"})
+				other, _ := mem.AddRevision(Revision{ArticleId: otherID, Title: "Другая"})
+
+				assert.Equal(t, 1, first.Number)
+				assert.Equal(t, 2, second.Number)
+				assert.Equal(t, 1, other.Number)
+				assert.NotEqual(t, uuid.Nil, first.Id)
+				assert.False(t, first.CreatedAt.IsZero())
+			},
+		},
+		{
+			name: "GetRevisions returns oldest first",
+			run: func(t *testing.T, mem *InMemoryRevision) {
+				articleID := uuid.New()
+				_, _ = mem.AddRevision(Revision{ArticleId: articleID, Title: "Первая"})
+				_, _ = mem.AddRevision(Revision{ArticleId: articleID, Title: "Вторая"})
+
+				got, err := mem.GetRevisions(articleID)
+				assert.NoError(t, err)
+				require.Len(t, got, 2)
+				assert.Equal(t, "Первая", got[0].Title)
+				assert.Equal(t, "Вторая", got[1].Title)
+			},
+		},
+		{
+			name: "GetRevisions returns empty slice for unknown article",
+			run: func(t *testing.T, mem *InMemoryRevision) {
+				got, err := mem.GetRevisions(uuid.New())
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+				assert.Empty(t, got)
+			},
+		},
+		{
+			name: "GetRevision finds by number",
+			run: func(t *testing.T, mem *InMemoryRevision) {
+				articleID := uuid.New()
+				_, _ = mem.AddRevision(Revision{ArticleId: articleID, Title: "Первая"})
+				_, _ = mem.AddRevision(Revision{ArticleId: articleID, Title: "Вторая"})
+
+				got, err := mem.GetRevision(articleID, 2)
+				assert.NoError(t, err)
+				assert.Equal(t, "Вторая", got.Title)
+
+				for _, number := range []int{0, 3} {
+					_, err = mem.GetRevision(articleID, number)
+					assert.ErrorIs(t, err, ErrRevisionNotFound)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mem := NewInMemoryRevision()
+			test.run(t, mem)
+		})
+	}
+}
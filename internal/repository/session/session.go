@@ -1,17 +1,22 @@
 package session
 
 import (
+	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrSessionNotFound = errors.New("session not found")
+
 type SessionRepository interface {
 	CreateSession() (*Session, error)
 	GetSessionById(sessionId uuid.UUID) (*Session, error)
 	SetSessionUserId(sessionId uuid.UUID, userId uuid.UUID) (*Session, error)
 	DeleteSessionById(sessionId uuid.UUID) (bool, error)
+	DeleteSessionsByUserId(userId uuid.UUID) (int, error)
 }
 
 type Session struct {
@@ -20,13 +25,15 @@ type Session struct {
 }
 
 type InMemorySession struct {
-	Sessions map[uuid.UUID]uuid.UUID
-	mu       sync.RWMutex
+	Sessions  map[uuid.UUID]uuid.UUID
+	createdAt map[uuid.UUID]time.Time
+	mu        sync.RWMutex
 }
 
 func NewInMemorySession() *InMemorySession {
 	return &InMemorySession{
-		Sessions: make(map[uuid.UUID]uuid.UUID),
+		Sessions:  make(map[uuid.UUID]uuid.UUID),
+		createdAt: make(map[uuid.UUID]time.Time),
 	}
 }
 
@@ -40,6 +47,7 @@ func (mem *InMemorySession) CreateSession() (*Session, error) {
 		SessionId: SessionId,
 	}
 	mem.Sessions[SessionId] = Session.UserId
+	mem.createdAt[SessionId] = time.Now()
 	return Session, nil
 }
 
@@ -54,7 +62,7 @@ func (mem *InMemorySession) GetSessionById(sessionId uuid.UUID) (*Session, error
 		}
 		return session, nil
 	} else {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 }
 
@@ -70,7 +78,7 @@ func (mem *InMemorySession) SetSessionUserId(sessionId uuid.UUID, userId uuid.UU
 		}
 		return session, nil
 	} else {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 }
 
@@ -80,8 +88,64 @@ func (mem *InMemorySession) DeleteSessionById(sessionId uuid.UUID) (bool, error)
 
 	if _, exists := mem.Sessions[sessionId]; exists {
 		delete(mem.Sessions, sessionId)
+		delete(mem.createdAt, sessionId)
 		return true, nil
 	} else {
-		return false, errors.New("session not found")
+		return false, ErrSessionNotFound
+	}
+}
+
+// DeleteSessionsByUserId logs the user out everywhere and returns how many
+// sessions were dropped.
+func (mem *InMemorySession) DeleteSessionsByUserId(userId uuid.UUID) (int, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	deleted := 0
+	for sessionId, owner := range mem.Sessions {
+		if owner == userId {
+			delete(mem.Sessions, sessionId)
+			delete(mem.createdAt, sessionId)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (mem *InMemorySession) Count() int {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	return len(mem.Sessions)
+}
+
+// DeleteExpired drops sessions older than ttl; their cookies have expired by then anyway.
+func (mem *InMemorySession) DeleteExpired(ttl time.Duration) int {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	deadline := time.Now().Add(-ttl)
+	deleted := 0
+	for sessionId, createdAt := range mem.createdAt {
+		if createdAt.Before(deadline) {
+			delete(mem.Sessions, sessionId)
+			delete(mem.createdAt, sessionId)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (mem *InMemorySession) RunJanitor(ctx context.Context, ttl time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mem.DeleteExpired(ttl)
+		}
 	}
 }
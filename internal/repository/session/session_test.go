@@ -2,6 +2,7 @@ package session_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 	"github.com/google/uuid"
@@ -25,6 +26,25 @@ func TestSession(t *testing.T) {
 				assert.True(t, exists)
 			},
 		},
+		{
+			name: "DeleteSessionsByUserId deletes only that user's sessions",
+			run: func(t *testing.T, mem *session.InMemorySession) {
+				userID := uuid.New()
+				first, _ := mem.CreateSession()
+				second, _ := mem.CreateSession()
+				other, _ := mem.CreateSession()
+				_, _ = mem.SetSessionUserId(first.SessionId, userID)
+				_, _ = mem.SetSessionUserId(second.SessionId, userID)
+				_, _ = mem.SetSessionUserId(other.SessionId, uuid.New())
+
+				deleted, err := mem.DeleteSessionsByUserId(userID)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, deleted)
+				assert.Equal(t, 1, mem.Count())
+				_, err = mem.GetSessionById(other.SessionId)
+				assert.NoError(t, err)
+			},
+		},
 		{
 			name: "GetSessionById returns existing session",
 			run: func(t *testing.T, mem *session.InMemorySession) {
@@ -83,6 +103,27 @@ func TestSession(t *testing.T) {
 				assert.EqualError(t, err, "session not found")
 			},
 		},
+		{
+			name: "DeleteExpired keeps fresh sessions",
+			run: func(t *testing.T, mem *session.InMemorySession) {
+				sess, _ := mem.CreateSession()
+				assert.Equal(t, 0, mem.DeleteExpired(time.Hour))
+
+				_, err := mem.GetSessionById(sess.SessionId)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "DeleteExpired removes old sessions",
+			run: func(t *testing.T, mem *session.InMemorySession) {
+				sess, _ := mem.CreateSession()
+				time.Sleep(5 * time.Millisecond)
+				assert.Equal(t, 1, mem.DeleteExpired(time.Millisecond))
+
+				_, err := mem.GetSessionById(sess.SessionId)
+				assert.EqualError(t, err, "session not found")
+			},
+		},
 	}
 
 	for _, test := range tests {
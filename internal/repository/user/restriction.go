@@ -0,0 +1,79 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RestrictionKind string
+
+const (
+	// RestrictionBan blocks login and ends every session of the user.
+	RestrictionBan RestrictionKind = "ban"
+	// RestrictionSuspension leaves the account read-only.
+	RestrictionSuspension RestrictionKind = "suspension"
+	// RestrictionShadowBan hides the user's content from everyone but the
+	// user, who isn't told about it.
+	RestrictionShadowBan RestrictionKind = "shadowban"
+)
+
+func RestrictionKinds() []RestrictionKind {
+	return []RestrictionKind{RestrictionBan, RestrictionSuspension, RestrictionShadowBan}
+}
+
+func ParseRestrictionKind(s string) (RestrictionKind, error) {
+	for _, kind := range RestrictionKinds() {
+		if string(kind) == s {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unknown restriction %q", s)
+}
+
+// Restriction is a moderator sanction. A nil ExpiresAt means it is permanent;
+// expired restrictions are ignored rather than deleted, so the history stays
+// visible to moderators until the next restriction of the same kind.
+type Restriction struct {
+	Kind      RestrictionKind `json:"kind"`
+	Reason    string          `json:"reason"`
+	IssuedBy  uuid.UUID       `json:"issued_by"`
+	IssuedAt  time.Time       `json:"issued_at"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+func (r Restriction) ActiveAt(now time.Time) bool {
+	return r.ExpiresAt == nil || now.Before(*r.ExpiresAt)
+}
+
+// Until describes the expiry for messages shown to the user.
+func (r Restriction) Until() string {
+	if r.ExpiresAt == nil {
+		return "permanently"
+	}
+	return "until " + r.ExpiresAt.UTC().Format(time.RFC3339)
+}
+
+// ActiveRestriction returns the user's unexpired restriction of the kind, or nil.
+func (u *User) ActiveRestriction(kind RestrictionKind) *Restriction {
+	now := time.Now()
+	for i := range u.Restrictions {
+		if u.Restrictions[i].Kind == kind && u.Restrictions[i].ActiveAt(now) {
+			r := u.Restrictions[i]
+			return &r
+		}
+	}
+	return nil
+}
+
+func (u *User) ActiveRestrictions() []Restriction {
+	now := time.Now()
+	active := make([]Restriction, 0, len(u.Restrictions))
+	for _, r := range u.Restrictions {
+		if r.ActiveAt(now) {
+			active = append(active, r)
+		}
+	}
+	return active
+}
@@ -2,25 +2,42 @@ package user
 
 import (
 	"errors"
+	"slices"
 	"sync"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("this user is already registered")
+)
+
 type UserRepository interface {
 	CreateUser(email string, password string, name string) (*User, error)
 	GetUserById(id uuid.UUID) (*User, error)
 	GetUserByEmail(email string) (*User, error)
 	GetAllUsers() ([]*User, error)
 	DeleteUser(id uuid.UUID) (bool, error)
+	GrantRole(id uuid.UUID, role rbac.Role) (*User, error)
+	RevokeRole(id uuid.UUID, role rbac.Role) (*User, error)
+	WarnUser(id uuid.UUID) (*User, error)
+	Restrict(id uuid.UUID, restriction Restriction) (*User, error)
+	LiftRestriction(id uuid.UUID, kind RestrictionKind) (*User, error)
+	GetRestrictedUserIds(kinds ...RestrictionKind) ([]uuid.UUID, error)
 }
 
 type User struct {
-	Id       uuid.UUID `json:"-"`
-	Email    string    `json:"email"`
-	Password string    `json:"-"`
-	Name     string    `json:"name"`
-	Avatar   string    `json:"avatar"`
+	Id       uuid.UUID   `json:"id"`
+	Email    string      `json:"email"`
+	Password string      `json:"-"`
+	Name     string      `json:"name"`
+	Avatar   string      `json:"avatar"`
+	Roles    []rbac.Role `json:"roles"`
+	Warnings int         `json:"-"`
+	// Restrictions holds at most one restriction per kind.
+	Restrictions []Restriction `json:"-"`
 }
 
 type InMemoryUser struct {
@@ -40,7 +57,7 @@ func (mem *InMemoryUser) CreateUser(email string, password string, name string)
 
 	for _, user := range mem.Users {
 		if user.Email == email {
-			return nil, errors.New("this user is already registered")
+			return nil, ErrUserExists
 		}
 	}
 	user := User{
@@ -48,11 +65,11 @@ func (mem *InMemoryUser) CreateUser(email string, password string, name string)
 		Email:    email,
 		Password: password,
 		Name:     name,
+		Roles:    []rbac.Role{rbac.RoleUser},
 		Avatar:   "https://sun9-88.userapi.com/s/v1/ig2/P_e5HW2lWX3ZxayBg73NnzbHzyhxFCXtBseRjSrN_NbemNC78OpkeYfJeXcTOXqyR8NhSwizZKqJEq_R8PhQo607.jpg?quality=95&as=32x40,48x60,72x90,108x135,160x200,240x300,360x450,480x600,540x675,640x800,720x900,1080x1350,1280x1600,1440x1800,1620x2025&from=bu&cs=1620x0",
 	}
 	mem.Users = append(mem.Users, user)
-	copyUser := user
-	return &copyUser, nil
+	return user.copy(), nil
 }
 
 func (mem *InMemoryUser) GetUserById(userID uuid.UUID) (*User, error) {
@@ -61,11 +78,10 @@ func (mem *InMemoryUser) GetUserById(userID uuid.UUID) (*User, error) {
 
 	for i := range mem.Users {
 		if mem.Users[i].Id == userID {
-			copyUser := mem.Users[i]
-			return &copyUser, nil
+			return mem.Users[i].copy(), nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, ErrUserNotFound
 }
 
 func (mem *InMemoryUser) GetUserByEmail(email string) (*User, error) {
@@ -74,11 +90,10 @@ func (mem *InMemoryUser) GetUserByEmail(email string) (*User, error) {
 
 	for i := range mem.Users {
 		if mem.Users[i].Email == email {
-			copyUser := mem.Users[i]
-			return &copyUser, nil
+			return mem.Users[i].copy(), nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, ErrUserNotFound
 }
 
 func (mem *InMemoryUser) GetAllUsers() ([]*User, error) {
@@ -86,8 +101,7 @@ func (mem *InMemoryUser) GetAllUsers() ([]*User, error) {
 	defer mem.mu.RUnlock()
 	usersCopy := make([]*User, len(mem.Users))
 	for i := range mem.Users {
-		temp := mem.Users[i]
-		usersCopy[i] = &temp
+		usersCopy[i] = mem.Users[i].copy()
 	}
 
 	return usersCopy, nil
@@ -104,5 +118,102 @@ func (mem *InMemoryUser) DeleteUser(userID uuid.UUID) (bool, error) {
 			return true, nil
 		}
 	}
-	return false, errors.New("user not found")
+	return false, ErrUserNotFound
+}
+
+func (mem *InMemoryUser) GrantRole(userID uuid.UUID, role rbac.Role) (*User, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Users {
+		if mem.Users[i].Id == userID {
+			if !slices.Contains(mem.Users[i].Roles, role) {
+				mem.Users[i].Roles = append(mem.Users[i].Roles, role)
+			}
+			return mem.Users[i].copy(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (mem *InMemoryUser) RevokeRole(userID uuid.UUID, role rbac.Role) (*User, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Users {
+		if mem.Users[i].Id == userID {
+			mem.Users[i].Roles = slices.DeleteFunc(mem.Users[i].Roles, func(r rbac.Role) bool { return r == role })
+			return mem.Users[i].copy(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// WarnUser counts moderator warnings; it doesn't restrict the account.
+func (mem *InMemoryUser) WarnUser(userID uuid.UUID) (*User, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Users {
+		if mem.Users[i].Id == userID {
+			mem.Users[i].Warnings++
+			return mem.Users[i].copy(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// Restrict replaces any earlier restriction of the same kind.
+func (mem *InMemoryUser) Restrict(userID uuid.UUID, restriction Restriction) (*User, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Users {
+		if mem.Users[i].Id == userID {
+			u := &mem.Users[i]
+			u.Restrictions = slices.DeleteFunc(u.Restrictions, func(r Restriction) bool { return r.Kind == restriction.Kind })
+			u.Restrictions = append(u.Restrictions, restriction)
+			return u.copy(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (mem *InMemoryUser) LiftRestriction(userID uuid.UUID, kind RestrictionKind) (*User, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := range mem.Users {
+		if mem.Users[i].Id == userID {
+			u := &mem.Users[i]
+			u.Restrictions = slices.DeleteFunc(u.Restrictions, func(r Restriction) bool { return r.Kind == kind })
+			return u.copy(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetRestrictedUserIds lists users with an unexpired restriction of any of
+// the kinds, so content filters can look them up once per request.
+func (mem *InMemoryUser) GetRestrictedUserIds(kinds ...RestrictionKind) ([]uuid.UUID, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	result := make([]uuid.UUID, 0)
+	for i := range mem.Users {
+		for _, kind := range kinds {
+			if mem.Users[i].ActiveRestriction(kind) != nil {
+				result = append(result, mem.Users[i].Id)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// copy keeps callers from mutating the stored roles and restrictions.
+func (u User) copy() *User {
+	u.Roles = slices.Clone(u.Roles)
+	u.Restrictions = slices.Clone(u.Restrictions)
+	return &u
 }
@@ -2,7 +2,9 @@ package user
 
 import (
 	"testing"
+	"time"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -114,6 +116,127 @@ func TestUser(t *testing.T) {
 				assert.EqualError(t, err, "user not found")
 			},
 		},
+		{
+			name: "CreateUser assigns the user role",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				assert.Equal(t, []rbac.Role{rbac.RoleUser}, u.Roles)
+			},
+		},
+		{
+			name: "GrantRole adds a role once",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				_, err := mem.GrantRole(u.Id, rbac.RoleModerator)
+				assert.NoError(t, err)
+				got, err := mem.GrantRole(u.Id, rbac.RoleModerator)
+				assert.NoError(t, err)
+				assert.Equal(t, []rbac.Role{rbac.RoleUser, rbac.RoleModerator}, got.Roles)
+			},
+		},
+		{
+			name: "RevokeRole removes a role",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				_, _ = mem.GrantRole(u.Id, rbac.RoleAuthor)
+				got, err := mem.RevokeRole(u.Id, rbac.RoleAuthor)
+				assert.NoError(t, err)
+				assert.Equal(t, []rbac.Role{rbac.RoleUser}, got.Roles)
+			},
+		},
+		{
+			name: "returned roles are copies",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				u.Roles[0] = rbac.RoleAdmin
+				got, _ := mem.GetUserById(u.Id)
+				assert.Equal(t, []rbac.Role{rbac.RoleUser}, got.Roles)
+			},
+		},
+		{
+			name: "GrantRole returns error if not found",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				_, err := mem.GrantRole(uuid.New(), rbac.RoleAdmin)
+				assert.EqualError(t, err, "user not found")
+			},
+		},
+		{
+			name: "WarnUser counts warnings",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				_, _ = mem.WarnUser(u.Id)
+				got, err := mem.WarnUser(u.Id)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, got.Warnings)
+			},
+		},
+		{
+			name: "WarnUser returns error if not found",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				_, err := mem.WarnUser(uuid.New())
+				assert.EqualError(t, err, "user not found")
+			},
+		},
+		{
+			name: "Restrict replaces a restriction of the same kind",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				_, _ = mem.Restrict(u.Id, Restriction{Kind: RestrictionBan, Reason: "first"})
+				_, _ = mem.Restrict(u.Id, Restriction{Kind: RestrictionSuspension, Reason: "other"})
+				got, err := mem.Restrict(u.Id, Restriction{Kind: RestrictionBan, Reason: "second"})
+				assert.NoError(t, err)
+				assert.Len(t, got.Restrictions, 2)
+				assert.Equal(t, "second", got.ActiveRestriction(RestrictionBan).Reason)
+			},
+		},
+		{
+			name: "expired restrictions are inactive",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+				_, _ = mem.Restrict(u.Id, Restriction{Kind: RestrictionBan, ExpiresAt: &past})
+				got, _ := mem.Restrict(u.Id, Restriction{Kind: RestrictionSuspension, ExpiresAt: &future})
+				assert.Nil(t, got.ActiveRestriction(RestrictionBan))
+				assert.NotNil(t, got.ActiveRestriction(RestrictionSuspension))
+				assert.Len(t, got.ActiveRestrictions(), 1)
+			},
+		},
+		{
+			name: "LiftRestriction removes the restriction",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				u, _ := mem.CreateUser("test@example.com", "password", "TestUser")
+				_, _ = mem.Restrict(u.Id, Restriction{Kind: RestrictionShadowBan})
+				got, err := mem.LiftRestriction(u.Id, RestrictionShadowBan)
+				assert.NoError(t, err)
+				assert.Nil(t, got.ActiveRestriction(RestrictionShadowBan))
+			},
+		},
+		{
+			name: "GetRestrictedUserIds lists users with active restrictions of the kinds",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				banned, _ := mem.CreateUser("banned@example.com", "password", "Banned")
+				suspended, _ := mem.CreateUser("suspended@example.com", "password", "Suspended")
+				expired, _ := mem.CreateUser("expired@example.com", "password", "Expired")
+				_, _ = mem.CreateUser("clean@example.com", "password", "Clean")
+				past := time.Now().Add(-time.Minute)
+				_, _ = mem.Restrict(banned.Id, Restriction{Kind: RestrictionBan})
+				_, _ = mem.Restrict(suspended.Id, Restriction{Kind: RestrictionSuspension})
+				_, _ = mem.Restrict(expired.Id, Restriction{Kind: RestrictionShadowBan, ExpiresAt: &past})
+
+				ids, err := mem.GetRestrictedUserIds(RestrictionBan, RestrictionShadowBan)
+				assert.NoError(t, err)
+				assert.Equal(t, []uuid.UUID{banned.Id}, ids)
+			},
+		},
+		{
+			name: "Restrict and LiftRestriction return error if not found",
+			run: func(t *testing.T, mem *InMemoryUser) {
+				_, err := mem.Restrict(uuid.New(), Restriction{Kind: RestrictionBan})
+				assert.EqualError(t, err, "user not found")
+				_, err = mem.LiftRestriction(uuid.New(), RestrictionBan)
+				assert.EqualError(t, err, "user not found")
+			},
+		},
 	}
 
 	for _, test := range tests {
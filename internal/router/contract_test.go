@@ -0,0 +1,385 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/health"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/openapi"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLimits = Limits{
+	Login:        middleware.RateLimit{Requests: 10, Window: time.Minute},
+	Registration: middleware.RateLimit{Requests: 5, Window: time.Minute},
+	Feed:         middleware.RateLimit{Requests: 60, Window: time.Minute},
+	Report:       middleware.RateLimit{Requests: 10, Window: time.Hour},
+}
+
+func newTestRoutes(users user.UserRepository) *Group {
+	return newRoutes(
+		session.NewInMemorySession(),
+		users,
+		article.NewInMemoryArticle(),
+		report.NewInMemoryReport(),
+		audit.NewInMemoryAuditLog(),
+		relation.NewInMemoryRelation(),
+		revision.NewInMemoryRevision(),
+		middleware.NewInMemoryRateLimitStore(),
+		testLimits,
+		middleware.NewCSRF([]byte("secret"), nil),
+		health.NewChecks(time.Second),
+	)
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	validator, err := openapi.NewValidator()
+	require.NoError(t, err)
+
+	assert.Equal(t, validator.Operations(), newTestRoutes(user.NewInMemoryUser()).Routes())
+}
+
+// contractClient checks every response it receives against the OpenAPI document.
+type contractClient struct {
+	t         *testing.T
+	server    *httptest.Server
+	client    *http.Client
+	validator *openapi.Validator
+	csrfToken string
+}
+
+func (c *contractClient) do(method, path, contentType string, body string) (int, []byte) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.server.URL+path, bytes.NewBufferString(body))
+	require.NoError(c.t, err)
+	req.Header.Set("Origin", c.server.URL)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.csrfToken != "" {
+		req.Header.Set(middleware.CSRFHeader, c.csrfToken)
+	}
+
+	resp, err := c.client.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	// login and registration send the token of the session they start
+	if token := resp.Header.Get(middleware.CSRFHeader); token != "" {
+		c.csrfToken = token
+	}
+
+	assert.NoError(c.t, c.validator.ValidateResponse(req, resp.StatusCode, resp.Header.Get("Content-Type"), data),
+		"%s %s", method, path)
+	return resp.StatusCode, data
+}
+
+// refreshCSRF is needed whenever the session changes outside login and
+// registration, since tokens are bound to it.
+func (c *contractClient) refreshCSRF() {
+	c.t.Helper()
+	c.csrfToken = ""
+	status, data := c.do(http.MethodGet, "/api/v1/csrf", "", "")
+	require.Equal(c.t, http.StatusOK, status)
+	var token struct {
+		CSRFToken string `json:"csrf_token"`
+	}
+	require.NoError(c.t, json.Unmarshal(data, &token))
+	c.csrfToken = token.CSRFToken
+}
+
+func TestContract(t *testing.T) {
+	validator, err := openapi.NewValidator()
+	require.NoError(t, err)
+
+	users := user.NewInMemoryUser()
+	admin, err := users.CreateUser("admin@mail.ru", "password1", "Admin")
+	require.NoError(t, err)
+	_, err = users.GrantRole(admin.Id, rbac.RoleAdmin)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(newTestRoutes(users))
+	defer server.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	// redirects are responses to check, not to follow
+	client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	c := &contractClient{t: t, server: server, client: client, validator: validator}
+	const jsonType = "application/json"
+
+	status, _ := c.do(http.MethodGet, "/api/v1/me", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	c.refreshCSRF()
+	status, _ = c.do(http.MethodPost, "/api/v1/registration", jsonType, `{"email":"bad","password":"password1","name":"Tester"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/registration", jsonType, `{"email":"user@mail.ru","password":"password1","name":"Tester"}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, _ = c.do(http.MethodPost, "/api/v1/registration", jsonType, `{"email":"user@mail.ru","password":"password1","name":"Tester"}`)
+	assert.Equal(t, http.StatusConflict, status)
+
+	status, data := c.do(http.MethodGet, "/api/v1/me", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var me struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(data, &me))
+
+	status, _ = c.do(http.MethodPut, "/api/v1/admin/users/"+me.ID+"/roles/author", "", "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, data = c.do(http.MethodGet, "/api/v1/feed", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var feed []struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(data, &feed))
+	require.NotEmpty(t, feed)
+
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+feed[0].ID, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+uuid.NewString(), "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/42", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+feed[0].ID+"/report", jsonType, `{"reason":"spam","details":"реклама"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+feed[0].ID+"/report", jsonType, `{"reason":"abuse"}`)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+feed[0].ID+"/report", jsonType, `{"reason":"boring"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/users/"+me.ID+"/report", jsonType, `{"reason":"spam"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/users/"+admin.Id.String()+"/report", jsonType, `{"reason":"harassment"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/moderation/reports", "", "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = c.do(http.MethodPut, "/api/v1/me/blocks/"+me.ID, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/blocks/"+uuid.NewString(), "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/blocks/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/me/blocks", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/me/blocks/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/mutes/users/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/mutes/keywords/x", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/mutes/keywords/SaaS", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/me/mutes", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, data = c.do(http.MethodGet, "/api/v1/feed", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var personalized []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &personalized))
+	assert.Len(t, personalized, len(feed)-1, "the SaaS article is muted")
+	status, _ = c.do(http.MethodDelete, "/api/v1/me/mutes/keywords/saas", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/me/mutes/users/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/me/follows/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/me/follows", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/me/follows/"+admin.Id.String(), "", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, data = c.do(http.MethodPost, "/api/v1/articles", jsonType, `{"title":"Черновик","content":"Текст","visibility":"unlisted"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	var draft struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(data, &draft))
+	status, _ = c.do(http.MethodPost, "/api/v1/articles", jsonType, `{"title":"","content":"Текст"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID+"/status", jsonType, `{"status":"published"}`)
+	assert.Equal(t, http.StatusForbidden, status, "publishing needs the author role")
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID+"/status", jsonType, `{"status":"archived"}`)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID+"/visibility", jsonType, `{"visibility":"private"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+feed[0].ID+"/visibility", jsonType, `{"visibility":"private"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID, jsonType, `{"title":"Черновик","content":"Новый текст"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID+"/revisions", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID+"/revisions/diff?from=1&to=2", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID+"/revisions/diff?from=1", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+draft.ID+"/revisions/1/restore", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+draft.ID+"/revisions/9/restore", "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+feed[0].ID+"/revisions", "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+draft.ID+"/convert-to-blocks", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles/"+draft.ID+"/convert-to-blocks", "", "")
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID, jsonType,
+		`{"title":"Черновик","content":"{\"version\":1,\"blocks\":[{\"type\":\"list\",\"data\":{\"style\":\"unordered\",\"items\":[]}}]}"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/articles/"+draft.ID, jsonType, `{"title":"Новый черновик","format":"markdown","content":"Текст"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID+"-novyy-chernovik", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID+"-chernovik", "", "")
+	assert.Equal(t, http.StatusMovedPermanently, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/articles", jsonType,
+		`{"title":"Блоки","format":"blocks","content":"{\"version\":1,\"blocks\":[{\"type\":\"paragraph\",\"data\":{\"text\":\"Текст\"}}]}"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	status, data = c.do(http.MethodGet, "/api/v1/me/articles", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(data), draft.ID)
+	status, _ = c.do(http.MethodDelete, "/api/v1/articles/"+feed[0].ID, "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/articles/"+draft.ID, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/articles/"+draft.ID, "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = c.do(http.MethodPost, "/api/v1/logout", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+draft.ID, "", "")
+	assert.Equal(t, http.StatusNotFound, status, "drafts are visible to their author only")
+
+	c.refreshCSRF()
+	status, _ = c.do(http.MethodPost, "/api/v1/login", "text/plain", `email=user@mail.ru`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"user@mail.ru","password":"password1","remember":true}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"nobody@mail.ru","password":"password1"}`)
+	assert.Equal(t, http.StatusUnauthorized, status, "unknown emails look like wrong passwords")
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"user@mail.ru","password":"wrong-password"}`)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"user@mail.ru","password":"password1"}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	c.csrfToken = ""
+	status, _ = c.do(http.MethodPost, "/api/v1/logout", "", "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	c.refreshCSRF()
+	status, _ = c.do(http.MethodPost, "/api/v1/logout", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	c.refreshCSRF()
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"admin@mail.ru","password":"password1"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/admin/users/"+me.ID+"/roles/author", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, "/api/v1/admin/users/"+me.ID+"/roles/root", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/admin/users/"+me.ID+"/roles/author", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, "/api/v1/admin/users/"+admin.Id.String()+"/roles/admin", "", "")
+	assert.Equal(t, http.StatusConflict, status)
+
+	status, data = c.do(http.MethodGet, "/api/v1/moderation/reports", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var queue []struct {
+		ID         string `json:"id"`
+		TargetType string `json:"target_type"`
+	}
+	require.NoError(t, json.Unmarshal(data, &queue))
+	require.Len(t, queue, 2)
+	status, _ = c.do(http.MethodGet, "/api/v1/moderation/reports?status=lost", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	for _, r := range queue {
+		action := `{"action":"hide","note":"spam"}`
+		if r.TargetType == "user" {
+			action = `{"action":"delete"}`
+			status, _ = c.do(http.MethodPost, "/api/v1/moderation/reports/"+r.ID+"/resolve", jsonType, action)
+			assert.Equal(t, http.StatusBadRequest, status)
+			action = `{"action":"warn"}`
+		}
+		status, _ = c.do(http.MethodPost, "/api/v1/moderation/reports/"+r.ID+"/claim", "", "")
+		assert.Equal(t, http.StatusOK, status)
+		status, _ = c.do(http.MethodPost, "/api/v1/moderation/reports/"+r.ID+"/resolve", jsonType, action)
+		assert.Equal(t, http.StatusOK, status)
+		status, _ = c.do(http.MethodPost, "/api/v1/moderation/reports/"+r.ID+"/resolve", jsonType, action)
+		assert.Equal(t, http.StatusConflict, status)
+	}
+	status, _ = c.do(http.MethodPost, "/api/v1/moderation/reports/"+uuid.NewString()+"/claim", "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+feed[0].ID, "", "")
+	assert.Equal(t, http.StatusOK, status, "moderators still see hidden articles")
+
+	status, data = c.do(http.MethodGet, "/api/v1/moderation/audit?limit=10", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var entries []struct {
+		Action string `json:"action"`
+	}
+	require.NoError(t, json.Unmarshal(data, &entries))
+	require.NotEmpty(t, entries)
+	status, _ = c.do(http.MethodGet, "/api/v1/moderation/audit?limit=0", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	restrictions := "/api/v1/moderation/users/" + me.ID + "/restrictions"
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	status, _ = c.do(http.MethodPut, restrictions+"/suspension", jsonType, `{"reason":"флуд","expires_at":"`+expiresAt+`"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodPut, restrictions+"/suspension", jsonType, `{"reason":"флуд","expires_at":"2000-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, restrictions+"/exile", jsonType, `{"reason":"флуд"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = c.do(http.MethodPut, restrictions+"/ban", jsonType, `{"reason":"спам"}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodDelete, restrictions+"/suspension", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, data = c.do(http.MethodGet, restrictions, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	var active struct {
+		Restrictions []struct {
+			Kind string `json:"kind"`
+		} `json:"restrictions"`
+	}
+	require.NoError(t, json.Unmarshal(data, &active))
+	require.Len(t, active.Restrictions, 1)
+	assert.Equal(t, "ban", active.Restrictions[0].Kind)
+
+	status, _ = c.do(http.MethodPost, "/api/v1/logout", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = c.do(http.MethodGet, "/api/v1/articles/"+feed[0].ID, "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	c.refreshCSRF()
+	status, _ = c.do(http.MethodPost, "/api/v1/login", jsonType, `{"email":"user@mail.ru","password":"password1"}`)
+	assert.Equal(t, http.StatusForbidden, status, "banned users can't log in")
+
+	for _, path := range []string{"/healthz", "/readyz", "/version", "/api/v1/openapi.json", "/api/v1/docs"} {
+		status, _ = c.do(http.MethodGet, path, "", "")
+		assert.Equal(t, http.StatusOK, status, path)
+	}
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/tracing"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/json"
+)
+
+type Middleware func(http.Handler) http.Handler
+
+// routes is shared by a root group and all groups derived from it.
+type routes struct {
+	mux     *http.ServeMux
+	methods map[string][]string
+}
+
+// Group registers method-aware routes under a common prefix and middleware.
+// Middleware of the parent group runs before middleware of the child group.
+type Group struct {
+	routes     *routes
+	prefix     string
+	middleware []Middleware
+}
+
+// NewGroup returns a root group whose unmatched paths get a JSON 404.
+func NewGroup() *Group {
+	g := &Group{routes: &routes{mux: http.NewServeMux(), methods: make(map[string][]string)}}
+	g.routes.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		json.WriteError(w, http.StatusNotFound, "not found")
+	})
+	return g
+}
+
+func (g *Group) Group(prefix string, middleware ...Middleware) *Group {
+	return &Group{
+		routes:     g.routes,
+		prefix:     g.prefix + prefix,
+		middleware: append(append([]Middleware{}, g.middleware...), middleware...),
+	}
+}
+
+func (g *Group) Use(middleware ...Middleware) {
+	g.middleware = append(g.middleware, middleware...)
+}
+
+// Handle registers h for method and path. Requests to the path with any
+// other method get a 405 listing the allowed methods.
+func (g *Group) Handle(method, path string, h http.Handler, middleware ...Middleware) {
+	route := g.prefix + path
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		h = g.middleware[i](h)
+	}
+	g.routes.mux.Handle(method+" "+route, metrics.Instrument(route, tracing.Instrument(route, h)))
+
+	if _, ok := g.routes.methods[route]; !ok {
+		g.routes.mux.HandleFunc(route, g.routes.methodNotAllowed(route))
+	}
+	g.routes.methods[route] = append(g.routes.methods[route], method)
+	if method == http.MethodGet {
+		g.routes.methods[route] = append(g.routes.methods[route], http.MethodHead)
+	}
+}
+
+func (g *Group) HandleFunc(method, path string, h http.HandlerFunc, middleware ...Middleware) {
+	g.Handle(method, path, h, middleware...)
+}
+
+// Routes lists every registered route as "METHOD path", sorted; HEAD is implied by GET.
+func (g *Group) Routes() []string {
+	var list []string
+	for route, methods := range g.routes.methods {
+		for _, method := range methods {
+			if method != http.MethodHead {
+				list = append(list, method+" "+route)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Methods lists the methods the routes accept, sorted, without the implied HEAD.
+func (g *Group) Methods() []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, methods := range g.routes.methods {
+		for _, method := range methods {
+			if method != http.MethodHead && !seen[method] {
+				seen[method] = true
+				list = append(list, method)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.routes.mux.ServeHTTP(w, r)
+}
+
+func (rs *routes) methodNotAllowed(route string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed := append([]string{}, rs.methods[route]...)
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		json.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	var trail []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trail = append(trail, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	root := NewGroup()
+	api := root.Group("/api/v1", mark("api"))
+	api.HandleFunc(http.MethodGet, "/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		trail = append(trail, "item "+r.PathValue("id"))
+	})
+	admin := api.Group("/admin", mark("admin"))
+	admin.HandleFunc(http.MethodPost, "/items", func(w http.ResponseWriter, r *http.Request) {
+		trail = append(trail, "create")
+	}, mark("route"))
+	admin.HandleFunc(http.MethodDelete, "/items", func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.Equal(t, []string{"DELETE", "GET", "POST"}, root.Methods())
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+		wantTrail  []string
+	}{
+		{name: "path parameter", method: http.MethodGet, path: "/api/v1/items/42", wantStatus: http.StatusOK, wantTrail: []string{"api", "item 42"}},
+		{name: "head follows get", method: http.MethodHead, path: "/api/v1/items/42", wantStatus: http.StatusOK, wantTrail: []string{"api", "item 42"}},
+		{name: "nested middleware order", method: http.MethodPost, path: "/api/v1/admin/items", wantStatus: http.StatusOK, wantTrail: []string{"api", "admin", "route", "create"}},
+		{name: "wrong method", method: http.MethodPost, path: "/api/v1/items/42", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD"},
+		{name: "allow lists every method", method: http.MethodGet, path: "/api/v1/admin/items", wantStatus: http.StatusMethodNotAllowed, wantAllow: "DELETE, POST"},
+		{name: "unprefixed path", method: http.MethodGet, path: "/items/42", wantStatus: http.StatusNotFound},
+		{name: "unknown path", method: http.MethodGet, path: "/api/v1/unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trail = nil
+			w := httptest.NewRecorder()
+
+			root.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			assert.Equal(t, tt.wantTrail, trail)
+			if tt.wantStatus >= http.StatusBadRequest {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -3,50 +3,260 @@ package router
 import (
 	"net/http"
 
+	adminhandler "github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/admin"
+	articlehandler "github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/csrf"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/feed"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/health"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/login"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/logout"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/moderation"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/registration"
+	relationhandler "github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/openapi"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/tracing"
 
 	handler "github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/me"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 )
 
-func NewRouter(sessions *session.InMemorySession, users *user.InMemoryUser, articles *article.InMemoryArticle) *http.ServeMux {
-	mux := http.NewServeMux()
-
-	mux.Handle("/feed", middleware.CORSMiddleware(http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			feed.FeedHandler(w, r, sessions, articles)
-		},
-	)))
-
-	mux.Handle("/registration", middleware.CORSMiddleware(http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			registration.RegistrationHandler(w, r, sessions, users)
-		},
-	)))
-
-	mux.Handle("/login", middleware.CORSMiddleware(http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			login.LoginHandler(w, r, sessions, users)
-		},
-	)))
-
-	mux.Handle("/logout", middleware.CORSMiddleware(http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			logout.LogoutHandler(w, r, sessions)
-		},
-	)))
-
-	mux.Handle("/me", middleware.CORSMiddleware(http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			handler.MeHandler(w, r, sessions, users)
-		},
-	)))
-
-	return mux
+// Limits are the rate limits of the routes that have one.
+type Limits struct {
+	Login        middleware.RateLimit
+	Registration middleware.RateLimit
+	Feed         middleware.RateLimit
+	Report       middleware.RateLimit
+}
+
+// Router serves the API. Methods lists the methods its routes accept, which
+// CORS allows unless the config names them.
+type Router struct {
+	http.Handler
+	Methods []string
+}
+
+func NewRouter(sessions session.SessionRepository, users user.UserRepository, articles article.ArticleRepository,
+	reports report.ReportRepository, auditLog audit.AuditLog, relations relation.RelationRepository,
+	revisions revision.RevisionRepository, limiter middleware.RateLimitStore, limits Limits, protector *middleware.CSRF,
+	checks *health.Checks, validator *openapi.Validator) *Router {
+	root := newRoutes(sessions, users, articles, reports, auditLog, relations, revisions, limiter, limits, protector, checks)
+	if validator != nil {
+		return &Router{Handler: validator.Middleware(root), Methods: root.Methods()}
+	}
+	return &Router{Handler: root, Methods: root.Methods()}
+}
+
+func newRoutes(sessions session.SessionRepository, users user.UserRepository, articles article.ArticleRepository,
+	reports report.ReportRepository, auditLog audit.AuditLog, relations relation.RelationRepository,
+	revisions revision.RevisionRepository, limiter middleware.RateLimitStore, limits Limits, protector *middleware.CSRF,
+	checks *health.Checks) *Group {
+	root := NewGroup()
+	authn := middleware.NewAuth(sessions, users)
+	api := root.Group("/api/v1")
+
+	// the docs live under /api/v1 so they go through the same nginx location as the API
+	api.HandleFunc(http.MethodGet, "/openapi.json", openapi.SpecHandler)
+	api.HandleFunc(http.MethodGet, "/docs", openapi.DocsHandler)
+
+	// routes that serve anonymous visitors too but behave differently for a known session
+	public := api.Group("", authn.OptionalAuth)
+
+	feedRateLimit := middleware.RateLimitMiddleware(limiter, limits.Feed, middleware.KeyByPrincipal)
+	public.HandleFunc(http.MethodGet, "/feed", func(w http.ResponseWriter, r *http.Request) {
+		feed.FeedHandler(w, r, tracing.Sessions(r.Context(), sessions), tracing.Articles(r.Context(), articles),
+			tracing.Users(r.Context(), users), tracing.Relations(r.Context(), relations))
+	}, feedRateLimit)
+
+	public.HandleFunc(http.MethodGet, "/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.ArticleHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Users(r.Context(), users),
+			tracing.Relations(r.Context(), relations))
+	})
+
+	public.HandleFunc(http.MethodGet, "/csrf", func(w http.ResponseWriter, r *http.Request) {
+		csrf.CSRFHandler(w, r, tracing.Sessions(r.Context(), sessions), protector)
+	})
+
+	private := api.Group("", authn.RequireAuth)
+
+	private.HandleFunc(http.MethodGet, "/me", handler.MeHandler)
+
+	private.HandleFunc(http.MethodGet, "/me/articles", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.MyArticlesHandler(w, r, tracing.Articles(r.Context(), articles))
+	})
+
+	private.HandleFunc(http.MethodGet, "/articles/{id}/revisions", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.RevisionsHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	})
+
+	private.HandleFunc(http.MethodGet, "/articles/{id}/revisions/diff", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.RevisionDiffHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	})
+
+	private.HandleFunc(http.MethodGet, "/me/blocks", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.GetBlocksHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	private.HandleFunc(http.MethodGet, "/me/mutes", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.GetMutesHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	private.HandleFunc(http.MethodGet, "/me/follows", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.GetFollowsHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	// state-changing routes need a CSRF token
+	protected := api.Group("", protector.Middleware)
+
+	registrationRateLimit := middleware.RateLimitMiddleware(limiter, limits.Registration, middleware.KeyByIP)
+	protected.HandleFunc(http.MethodPost, "/registration", func(w http.ResponseWriter, r *http.Request) {
+		registration.RegistrationHandler(w, r, tracing.Sessions(r.Context(), sessions), tracing.Users(r.Context(), users), protector)
+	}, registrationRateLimit)
+
+	loginRateLimit := middleware.RateLimitMiddleware(limiter, limits.Login, middleware.KeyByIP)
+	protected.HandleFunc(http.MethodPost, "/login", func(w http.ResponseWriter, r *http.Request) {
+		login.LoginHandler(w, r, tracing.Sessions(r.Context(), sessions), tracing.Users(r.Context(), users), protector)
+	}, loginRateLimit)
+
+	protectedPrivate := protected.Group("", authn.RequireAuth)
+
+	protectedPrivate.HandleFunc(http.MethodPost, "/logout", func(w http.ResponseWriter, r *http.Request) {
+		logout.LogoutHandler(w, r, tracing.Sessions(r.Context(), sessions))
+	})
+
+	// blocking and muting protect the user, so suspended users keep them
+	protectedPrivate.HandleFunc(http.MethodPut, "/me/blocks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.BlockHandler(w, r, tracing.Users(r.Context(), users), tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodDelete, "/me/blocks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.UnblockHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodPut, "/me/mutes/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.MuteUserHandler(w, r, tracing.Users(r.Context(), users), tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodDelete, "/me/mutes/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.UnmuteUserHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodPut, "/me/mutes/keywords/{keyword}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.MuteKeywordHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodDelete, "/me/mutes/keywords/{keyword}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.UnmuteKeywordHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodPut, "/me/follows/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.FollowHandler(w, r, tracing.Users(r.Context(), users), tracing.Relations(r.Context(), relations))
+	})
+
+	protectedPrivate.HandleFunc(http.MethodDelete, "/me/follows/{id}", func(w http.ResponseWriter, r *http.Request) {
+		relationhandler.UnfollowHandler(w, r, tracing.Relations(r.Context(), relations))
+	})
+
+	// logout stays open to suspended users; everything below is read-only for them
+	writable := protectedPrivate.Group("", middleware.RejectSuspended)
+
+	writable.HandleFunc(http.MethodPost, "/articles", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.CreateArticleHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	}, middleware.RequirePermission(rbac.ArticleCreate))
+
+	editing := writable.Group("", middleware.RequirePermission(rbac.ArticleUpdateOwn))
+
+	editing.HandleFunc(http.MethodPut, "/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.UpdateArticleHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	})
+
+	editing.HandleFunc(http.MethodPost, "/articles/{id}/revisions/{number}/restore", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.RestoreRevisionHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	})
+
+	editing.HandleFunc(http.MethodPut, "/articles/{id}/status", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.SetStatusHandler(w, r, tracing.Articles(r.Context(), articles))
+	})
+
+	editing.HandleFunc(http.MethodPut, "/articles/{id}/visibility", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.SetVisibilityHandler(w, r, tracing.Articles(r.Context(), articles))
+	})
+
+	editing.HandleFunc(http.MethodPost, "/articles/{id}/convert-to-blocks", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.ConvertToBlocksHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Revisions(r.Context(), revisions))
+	})
+
+	writable.HandleFunc(http.MethodDelete, "/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		articlehandler.DeleteArticleHandler(w, r, tracing.Articles(r.Context(), articles))
+	}, middleware.RequirePermission(rbac.ArticleDeleteOwn))
+
+	reporting := writable.Group("", middleware.RequirePermission(rbac.ReportCreate))
+	reportRateLimit := middleware.RateLimitMiddleware(limiter, limits.Report, middleware.KeyByPrincipal)
+
+	reporting.HandleFunc(http.MethodPost, "/articles/{id}/report", func(w http.ResponseWriter, r *http.Request) {
+		moderation.ReportArticleHandler(w, r, tracing.Articles(r.Context(), articles), tracing.Users(r.Context(), users),
+			tracing.Relations(r.Context(), relations), tracing.Reports(r.Context(), reports))
+	}, reportRateLimit)
+
+	reporting.HandleFunc(http.MethodPost, "/users/{id}/report", func(w http.ResponseWriter, r *http.Request) {
+		moderation.ReportUserHandler(w, r, tracing.Users(r.Context(), users), tracing.Reports(r.Context(), reports))
+	}, reportRateLimit)
+
+	// the CSRF check skips the GET routes, so the queue can share the group
+	moderators := writable.Group("/moderation", middleware.RequirePermission(rbac.ReportReview))
+
+	moderators.HandleFunc(http.MethodGet, "/reports", func(w http.ResponseWriter, r *http.Request) {
+		moderation.ListReportsHandler(w, r, tracing.Reports(r.Context(), reports))
+	})
+
+	moderators.HandleFunc(http.MethodPost, "/reports/{id}/claim", func(w http.ResponseWriter, r *http.Request) {
+		moderation.ClaimReportHandler(w, r, tracing.Reports(r.Context(), reports), tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	moderators.HandleFunc(http.MethodPost, "/reports/{id}/resolve", func(w http.ResponseWriter, r *http.Request) {
+		moderation.ResolveReportHandler(w, r, tracing.Reports(r.Context(), reports), tracing.Articles(r.Context(), articles),
+			tracing.Users(r.Context(), users), tracing.Sessions(r.Context(), sessions), tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	moderators.HandleFunc(http.MethodGet, "/users/{id}/restrictions", func(w http.ResponseWriter, r *http.Request) {
+		moderation.GetRestrictionsHandler(w, r, tracing.Users(r.Context(), users))
+	})
+
+	moderators.HandleFunc(http.MethodPut, "/users/{id}/restrictions/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		moderation.RestrictUserHandler(w, r, tracing.Users(r.Context(), users), tracing.Sessions(r.Context(), sessions),
+			tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	moderators.HandleFunc(http.MethodDelete, "/users/{id}/restrictions/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		moderation.LiftRestrictionHandler(w, r, tracing.Users(r.Context(), users), tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	moderators.HandleFunc(http.MethodGet, "/audit", func(w http.ResponseWriter, r *http.Request) {
+		moderation.AuditHandler(w, r, tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	admin := writable.Group("/admin", middleware.RequirePermission(rbac.RoleManage))
+
+	admin.HandleFunc(http.MethodPut, "/users/{id}/roles/{role}", func(w http.ResponseWriter, r *http.Request) {
+		adminhandler.GrantRoleHandler(w, r, tracing.Users(r.Context(), users), tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	admin.HandleFunc(http.MethodDelete, "/users/{id}/roles/{role}", func(w http.ResponseWriter, r *http.Request) {
+		adminhandler.RevokeRoleHandler(w, r, tracing.Users(r.Context(), users), tracing.AuditLog(r.Context(), auditLog))
+	})
+
+	root.HandleFunc(http.MethodGet, "/healthz", health.HealthzHandler)
+	root.HandleFunc(http.MethodGet, "/readyz", func(w http.ResponseWriter, r *http.Request) {
+		health.ReadyzHandler(w, r, checks)
+	})
+	root.HandleFunc(http.MethodGet, "/version", health.VersionHandler)
+
+	return root
 }
@@ -1,34 +1,327 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/config"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/cookies"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/handler/health"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/metrics"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
 
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/middleware"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
 
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/openapi"
 	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/router"
 )
 
-func StartServer() {
+const (
+	sessionJanitorInterval   = time.Minute
+	articleSchedulerInterval = 15 * time.Second
+	readinessCheckTimeout    = 2 * time.Second
+)
+
+// Worker is a background job that runs until its context is cancelled.
+type Worker struct {
+	Name string
+	Run  func(ctx context.Context)
+}
+
+type Server struct {
+	http *http.Server
+	// metrics is nil when metrics are off
+	metrics         *http.Server
+	workers         []Worker
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	logger          *slog.Logger
+	checks          *health.Checks
+}
+
+func New(cfg *config.Config, logger *slog.Logger) (*Server, error) {
+	if err := configureCookies(cfg.Cookie); err != nil {
+		return nil, err
+	}
+
+	csrfSecret, err := secretOrRandom(cfg.CSRF.Secret)
+	if err != nil {
+		return nil, err
+	}
+
 	sessions := session.NewInMemorySession()
 	users := user.NewInMemoryUser()
 	articles := article.NewInMemoryArticle()
+	reports := report.NewInMemoryReport()
+	auditLog := audit.NewInMemoryAuditLog()
+	relations := relation.NewInMemoryRelation()
+	revisions := revision.NewInMemoryRevision()
+	metrics.SetActiveSessionsSource(sessions.Count)
+
+	if cfg.Admin.Email != "" {
+		if err := bootstrapAdmin(users, cfg.Admin); err != nil {
+			return nil, fmt.Errorf("bootstrap admin: %w", err)
+		}
+		logger.Info("admin bootstrapped", "email", cfg.Admin.Email)
+	}
+
+	limiter := middleware.NewInMemoryRateLimitStore()
+
+	limits := router.Limits{
+		Login:        rateLimit(cfg.RateLimit.Login),
+		Registration: rateLimit(cfg.RateLimit.Registration),
+		Feed:         rateLimit(cfg.RateLimit.Feed),
+		Report:       rateLimit(cfg.RateLimit.Report),
+	}
+
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	protector := middleware.NewCSRF(csrfSecret, cfg.CORS.AllowedOrigins)
+
+	checks := health.NewChecks(readinessCheckTimeout)
+	checks.Register("sessions", func(ctx context.Context) error {
+		sessions.Count()
+		return nil
+	})
+	checks.Register("users", func(ctx context.Context) error {
+		_, err := users.GetAllUsers()
+		return err
+	})
+	checks.Register("articles", func(ctx context.Context) error {
+		_, err := articles.GetAllArticles()
+		return err
+	})
+
+	var validator *openapi.Validator
+	if cfg.OpenAPI.Validate {
+		validator, err = openapi.NewValidator()
+		if err != nil {
+			return nil, fmt.Errorf("load openapi document: %w", err)
+		}
+	}
+
+	mux := router.NewRouter(sessions, users, articles, reports, auditLog, relations, revisions, limiter, limits, protector, checks, validator)
+
+	corsConfig := middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		MaxAge:           cfg.CORS.MaxAge,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+	}
+	if len(corsConfig.AllowedMethods) == 0 {
+		corsConfig.AllowedMethods = mux.Methods
+	}
+	handler := middleware.RealIP(trustedProxies)(
+		middleware.LoggingMiddleware(logger)(middleware.RecoverMiddleware(middleware.NewCORS(corsConfig).Middleware(mux))))
 
-	mux := router.NewRouter(sessions, users, articles)
-	handler := middleware.CORSMiddleware(mux)
+	s := &Server{
+		http: &http.Server{
+			Addr:         cfg.Server.Addr,
+			Handler:      handler,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+			ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		},
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		drainDelay:      cfg.Server.DrainDelay,
+		logger:          logger,
+		checks:          checks,
+	}
 
-	server := http.Server{
-		Addr:         ":8090",
-		Handler:      handler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+	if cfg.Server.MetricsAddr != "" {
+		s.metrics = &http.Server{
+			Addr:              cfg.Server.MetricsAddr,
+			Handler:           metrics.Handler(),
+			ReadHeaderTimeout: cfg.Server.ReadTimeout,
+			ErrorLog:          s.http.ErrorLog,
+		}
 	}
 
-	fmt.Println("starting server at :8090")
-	server.ListenAndServe()
+	s.AddWorker(Worker{
+		Name: "session janitor",
+		Run: func(ctx context.Context) {
+			sessions.RunJanitor(ctx, cfg.Cookie.TTL, sessionJanitorInterval)
+		},
+	})
+
+	s.AddWorker(Worker{
+		Name: "article scheduler",
+		Run: func(ctx context.Context) {
+			articles.RunScheduler(ctx, articleSchedulerInterval)
+		},
+	})
+
+	return s, nil
+}
+
+// AddWorker registers a background job. Workers start in registration order
+// and are stopped in reverse order once the HTTP server has drained.
+func (s *Server) AddWorker(w Worker) {
+	s.workers = append(s.workers, w)
+}
+
+// Run serves until ctx is cancelled, then fails readiness for the drain
+// delay, drains in-flight requests and stops the workers. A listen failure
+// is returned immediately.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", s.http.Addr, err)
+	}
+	var metricsListener net.Listener
+	if s.metrics != nil {
+		metricsListener, err = net.Listen("tcp", s.metrics.Addr)
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("listen %s: %w", s.metrics.Addr, err)
+		}
+	}
+
+	stops := make([]func(), 0, len(s.workers))
+	dones := make([]chan struct{}, 0, len(s.workers))
+	for _, w := range s.workers {
+		workerCtx, stop := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func(w Worker) {
+			defer close(done)
+			w.Run(workerCtx)
+		}(w)
+		stops = append(stops, stop)
+		dones = append(dones, done)
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		s.logger.Info("starting server", "addr", listener.Addr().String())
+		serveErr <- s.http.Serve(listener)
+	}()
+	if s.metrics != nil {
+		go func() {
+			s.logger.Info("serving metrics", "addr", metricsListener.Addr().String())
+			serveErr <- s.metrics.Serve(metricsListener)
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = err
+		}
+	}
+
+	// readiness fails first, and the listener stays open for the drain delay
+	// so load balancers see it and stop routing here before connections are refused
+	s.logger.Info("shutting down", "drain_delay", s.drainDelay, "timeout", s.shutdownTimeout)
+	s.checks.SetShuttingDown()
+	if runErr == nil {
+		time.Sleep(s.drainDelay)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("http shutdown: %w", err))
+	}
+	// metrics outlive the API so the last scrape still sees the drain
+	if s.metrics != nil {
+		if err := s.metrics.Shutdown(shutdownCtx); err != nil {
+			runErr = errors.Join(runErr, fmt.Errorf("metrics shutdown: %w", err))
+		}
+	}
+
+	for i := len(s.workers) - 1; i >= 0; i-- {
+		stops[i]()
+		select {
+		case <-dones[i]:
+			s.logger.Info("worker stopped", "worker", s.workers[i].Name)
+		case <-shutdownCtx.Done():
+			runErr = errors.Join(runErr, fmt.Errorf("worker %q did not stop in time", s.workers[i].Name))
+		}
+	}
+
+	return runErr
+}
+
+func rateLimit(limit config.Limit) middleware.RateLimit {
+	return middleware.RateLimit{Requests: limit.Requests, Window: limit.Window}
+}
+
+func configureCookies(cfg config.CookieConfig) error {
+	var keys [][]byte
+	for _, key := range cfg.Keys {
+		keys = append(keys, []byte(key))
+	}
+	if len(keys) == 0 {
+		// without configured keys sessions don't survive a restart, which is fine for in-memory storage
+		key, err := secretOrRandom("")
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return cookies.Configure(cookies.Config{
+		Name:       cfg.Name,
+		Keys:       keys,
+		TTL:        cfg.TTL,
+		Secure:     cfg.Secure,
+		SameSite:   sameSite,
+		Domain:     cfg.Domain,
+		HostPrefix: cfg.HostPrefix,
+	})
+}
+
+// bootstrapAdmin never resets the password of an existing account, so
+// restarting with a stale admin.password can't lock its owner out.
+func bootstrapAdmin(users user.UserRepository, cfg config.AdminConfig) error {
+	admin, err := users.GetUserByEmail(cfg.Email)
+	if err != nil {
+		name, _, _ := strings.Cut(cfg.Email, "@")
+		admin, err = users.CreateUser(cfg.Email, string(cfg.Password), name)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = users.GrantRole(admin.Id, rbac.RoleAdmin)
+	return err
+}
+
+func secretOrRandom(secret config.Secret) ([]byte, error) {
+	if secret != "" {
+		return []byte(secret), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
 }
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/config"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/stretchr/testify/assert"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, cfg *config.Config)
+	}{
+		{
+			name: "listen failure is returned",
+			run: func(t *testing.T, cfg *config.Config) {
+				busy, err := net.Listen("tcp", "127.0.0.1:0")
+				assert.NoError(t, err)
+				defer busy.Close()
+				cfg.Server.Addr = busy.Addr().String()
+
+				srv, err := New(cfg, discard)
+				assert.NoError(t, err)
+				assert.ErrorContains(t, srv.Run(context.Background()), "listen")
+			},
+		},
+		{
+			name: "metrics listen failure is returned",
+			run: func(t *testing.T, cfg *config.Config) {
+				busy, err := net.Listen("tcp", "127.0.0.1:0")
+				assert.NoError(t, err)
+				defer busy.Close()
+				cfg.Server.MetricsAddr = busy.Addr().String()
+
+				srv, err := New(cfg, discard)
+				assert.NoError(t, err)
+				assert.ErrorContains(t, srv.Run(context.Background()), "listen "+busy.Addr().String())
+			},
+		},
+		{
+			name: "workers stop in reverse order after shutdown",
+			run: func(t *testing.T, cfg *config.Config) {
+				srv, err := New(cfg, discard)
+				assert.NoError(t, err)
+
+				var mu sync.Mutex
+				var stopped []string
+				for _, name := range []string{"first", "second"} {
+					srv.AddWorker(Worker{Name: name, Run: func(ctx context.Context) {
+						<-ctx.Done()
+						mu.Lock()
+						stopped = append(stopped, name)
+						mu.Unlock()
+					}})
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+
+				assert.NoError(t, srv.Run(ctx))
+				assert.Equal(t, []string{"second", "first"}, stopped)
+			},
+		},
+		{
+			name: "readiness fails while the listener drains",
+			run: func(t *testing.T, cfg *config.Config) {
+				free, err := net.Listen("tcp", "127.0.0.1:0")
+				assert.NoError(t, err)
+				cfg.Server.Addr = free.Addr().String()
+				free.Close()
+				cfg.Server.DrainDelay = 300 * time.Millisecond
+
+				srv, err := New(cfg, discard)
+				assert.NoError(t, err)
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() { done <- srv.Run(ctx) }()
+
+				readyz := "http://" + cfg.Server.Addr + "/readyz"
+				assert.Eventually(t, func() bool {
+					resp, err := http.Get(readyz)
+					if err != nil {
+						return false
+					}
+					resp.Body.Close()
+					return resp.StatusCode == http.StatusOK
+				}, time.Second, 10*time.Millisecond)
+
+				cancel()
+				time.Sleep(50 * time.Millisecond)
+				resp, err := http.Get(readyz)
+				if assert.NoError(t, err, "the listener stays open during the drain delay") {
+					resp.Body.Close()
+					assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				}
+				assert.NoError(t, <-done)
+			},
+		},
+		{
+			name: "stuck worker is reported",
+			run: func(t *testing.T, cfg *config.Config) {
+				cfg.Server.ShutdownTimeout = 20 * time.Millisecond
+				srv, err := New(cfg, discard)
+				assert.NoError(t, err)
+				srv.AddWorker(Worker{Name: "stuck", Run: func(ctx context.Context) {
+					time.Sleep(time.Second)
+				}})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				assert.ErrorContains(t, srv.Run(ctx), `worker "stuck" did not stop in time`)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Server.Addr = "127.0.0.1:0"
+			cfg.Server.MetricsAddr = "127.0.0.1:0"
+			cfg.Server.DrainDelay = 0
+			test.run(t, cfg)
+		})
+	}
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	cfg := config.AdminConfig{Email: "admin@mindleak.ru", Password: "correct-horse"}
+
+	t.Run("creates the account", func(t *testing.T) {
+		users := user.NewInMemoryUser()
+		assert.NoError(t, bootstrapAdmin(users, cfg))
+
+		admin, err := users.GetUserByEmail(cfg.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, "correct-horse", admin.Password)
+		assert.Equal(t, "admin", admin.Name)
+		assert.True(t, rbac.Can(admin.Roles, rbac.RoleManage))
+	})
+
+	t.Run("promotes an existing account", func(t *testing.T) {
+		users := user.NewInMemoryUser()
+		existing, _ := users.CreateUser(cfg.Email, "old-password", "Owner")
+		assert.NoError(t, bootstrapAdmin(users, cfg))
+		assert.NoError(t, bootstrapAdmin(users, cfg), "must be idempotent")
+
+		admin, err := users.GetUserById(existing.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "old-password", admin.Password, "password must not be reset")
+		assert.Equal(t, []rbac.Role{rbac.RoleUser, rbac.RoleAdmin}, admin.Roles)
+	})
+}
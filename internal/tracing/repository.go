@@ -0,0 +1,405 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/audit"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/report"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/revision"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// The repositories don't take a context, so the decorators are built per
+// request around the request context; their spans become children of the
+// HTTP span.
+
+type sessionRepository struct {
+	ctx  context.Context
+	next session.SessionRepository
+}
+
+func Sessions(ctx context.Context, next session.SessionRepository) session.SessionRepository {
+	return &sessionRepository{ctx: ctx, next: next}
+}
+
+func (t *sessionRepository) CreateSession() (*session.Session, error) {
+	_, span := startSpan(t.ctx, "SessionRepository.CreateSession")
+	s, err := t.next.CreateSession()
+	endSpan(span, err)
+	return s, err
+}
+
+func (t *sessionRepository) GetSessionById(sessionId uuid.UUID) (*session.Session, error) {
+	// session ids are bearer credentials and must not reach the trace backend
+	_, span := startSpan(t.ctx, "SessionRepository.GetSessionById")
+	s, err := t.next.GetSessionById(sessionId)
+	if err == nil && s.UserId != uuid.Nil {
+		span.SetAttributes(attribute.String("user.id", s.UserId.String()))
+	}
+	endSpan(span, err)
+	return s, err
+}
+
+func (t *sessionRepository) SetSessionUserId(sessionId uuid.UUID, userId uuid.UUID) (*session.Session, error) {
+	_, span := startSpan(t.ctx, "SessionRepository.SetSessionUserId", attribute.String("user.id", userId.String()))
+	s, err := t.next.SetSessionUserId(sessionId, userId)
+	endSpan(span, err)
+	return s, err
+}
+
+func (t *sessionRepository) DeleteSessionById(sessionId uuid.UUID) (bool, error) {
+	_, span := startSpan(t.ctx, "SessionRepository.DeleteSessionById")
+	ok, err := t.next.DeleteSessionById(sessionId)
+	endSpan(span, err)
+	return ok, err
+}
+
+func (t *sessionRepository) DeleteSessionsByUserId(userId uuid.UUID) (int, error) {
+	_, span := startSpan(t.ctx, "SessionRepository.DeleteSessionsByUserId", attribute.String("user.id", userId.String()))
+	n, err := t.next.DeleteSessionsByUserId(userId)
+	endSpan(span, err)
+	return n, err
+}
+
+type userRepository struct {
+	ctx  context.Context
+	next user.UserRepository
+}
+
+func Users(ctx context.Context, next user.UserRepository) user.UserRepository {
+	return &userRepository{ctx: ctx, next: next}
+}
+
+func (t *userRepository) CreateUser(email string, password string, name string) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.CreateUser")
+	u, err := t.next.CreateUser(email, password, name)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) GetUserById(id uuid.UUID) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.GetUserById", attribute.String("user.id", id.String()))
+	u, err := t.next.GetUserById(id)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) GetUserByEmail(email string) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.GetUserByEmail")
+	u, err := t.next.GetUserByEmail(email)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) GetAllUsers() ([]*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.GetAllUsers")
+	users, err := t.next.GetAllUsers()
+	endSpan(span, err)
+	return users, err
+}
+
+func (t *userRepository) DeleteUser(id uuid.UUID) (bool, error) {
+	_, span := startSpan(t.ctx, "UserRepository.DeleteUser", attribute.String("user.id", id.String()))
+	ok, err := t.next.DeleteUser(id)
+	endSpan(span, err)
+	return ok, err
+}
+
+func (t *userRepository) GrantRole(id uuid.UUID, role rbac.Role) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.GrantRole", attribute.String("user.id", id.String()), attribute.String("user.role", string(role)))
+	u, err := t.next.GrantRole(id, role)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) RevokeRole(id uuid.UUID, role rbac.Role) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.RevokeRole", attribute.String("user.id", id.String()), attribute.String("user.role", string(role)))
+	u, err := t.next.RevokeRole(id, role)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) WarnUser(id uuid.UUID) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.WarnUser", attribute.String("user.id", id.String()))
+	u, err := t.next.WarnUser(id)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) Restrict(id uuid.UUID, restriction user.Restriction) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.Restrict", attribute.String("user.id", id.String()),
+		attribute.String("user.restriction", string(restriction.Kind)))
+	u, err := t.next.Restrict(id, restriction)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) LiftRestriction(id uuid.UUID, kind user.RestrictionKind) (*user.User, error) {
+	_, span := startSpan(t.ctx, "UserRepository.LiftRestriction", attribute.String("user.id", id.String()),
+		attribute.String("user.restriction", string(kind)))
+	u, err := t.next.LiftRestriction(id, kind)
+	endSpan(span, err)
+	return u, err
+}
+
+func (t *userRepository) GetRestrictedUserIds(kinds ...user.RestrictionKind) ([]uuid.UUID, error) {
+	_, span := startSpan(t.ctx, "UserRepository.GetRestrictedUserIds")
+	ids, err := t.next.GetRestrictedUserIds(kinds...)
+	endSpan(span, err)
+	return ids, err
+}
+
+type articleRepository struct {
+	ctx  context.Context
+	next article.ArticleRepository
+}
+
+func Articles(ctx context.Context, next article.ArticleRepository) article.ArticleRepository {
+	return &articleRepository{ctx: ctx, next: next}
+}
+
+func (t *articleRepository) CreateArticle(author article.Author, title string, format article.Format, content string, record article.Record) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.CreateArticle",
+		attribute.String("user.id", author.Id.String()), attribute.String("article.format", string(format)))
+	a, err := t.next.CreateArticle(author, title, format, content, record)
+	endSpan(span, err)
+	return a, err
+}
+
+func (t *articleRepository) GetArticleById(id uuid.UUID) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.GetArticleById", attribute.String("article.id", id.String()))
+	a, err := t.next.GetArticleById(id)
+	endSpan(span, err)
+	return a, err
+}
+
+func (t *articleRepository) GetArticlesByAuthorId(authorId uuid.UUID) ([]*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.GetArticlesByAuthorId", attribute.String("user.id", authorId.String()))
+	articles, err := t.next.GetArticlesByAuthorId(authorId)
+	endSpan(span, err)
+	return articles, err
+}
+
+func (t *articleRepository) GetAllArticles() ([]*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.GetAllArticles")
+	articles, err := t.next.GetAllArticles()
+	endSpan(span, err)
+	return articles, err
+}
+
+func (t *articleRepository) DeleteArticle(id uuid.UUID) (bool, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.DeleteArticle", attribute.String("article.id", id.String()))
+	ok, err := t.next.DeleteArticle(id)
+	endSpan(span, err)
+	return ok, err
+}
+
+func (t *articleRepository) HideArticle(id uuid.UUID) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.HideArticle", attribute.String("article.id", id.String()))
+	a, err := t.next.HideArticle(id)
+	endSpan(span, err)
+	return a, err
+}
+
+func (t *articleRepository) UpdateArticle(id uuid.UUID, title string, format article.Format, content string, record article.Record) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.UpdateArticle",
+		attribute.String("article.id", id.String()), attribute.String("article.format", string(format)))
+	a, err := t.next.UpdateArticle(id, title, format, content, record)
+	endSpan(span, err)
+	return a, err
+}
+
+func (t *articleRepository) SetStatus(id uuid.UUID, status article.Status, publishAt *time.Time) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.SetStatus",
+		attribute.String("article.id", id.String()), attribute.String("article.status", string(status)))
+	a, err := t.next.SetStatus(id, status, publishAt)
+	endSpan(span, err)
+	return a, err
+}
+
+func (t *articleRepository) SetVisibility(id uuid.UUID, visibility article.Visibility) (*article.Article, error) {
+	_, span := startSpan(t.ctx, "ArticleRepository.SetVisibility",
+		attribute.String("article.id", id.String()), attribute.String("article.visibility", string(visibility)))
+	a, err := t.next.SetVisibility(id, visibility)
+	endSpan(span, err)
+	return a, err
+}
+
+type reportRepository struct {
+	ctx  context.Context
+	next report.ReportRepository
+}
+
+func Reports(ctx context.Context, next report.ReportRepository) report.ReportRepository {
+	return &reportRepository{ctx: ctx, next: next}
+}
+
+func (t *reportRepository) CreateReport(targetType report.TargetType, targetId, reporterId uuid.UUID, reason report.Reason, details string) (*report.Report, error) {
+	_, span := startSpan(t.ctx, "ReportRepository.CreateReport",
+		attribute.String("report.target_type", string(targetType)), attribute.String("report.target_id", targetId.String()))
+	r, err := t.next.CreateReport(targetType, targetId, reporterId, reason, details)
+	endSpan(span, err)
+	return r, err
+}
+
+func (t *reportRepository) GetReportById(id uuid.UUID) (*report.Report, error) {
+	_, span := startSpan(t.ctx, "ReportRepository.GetReportById", attribute.String("report.id", id.String()))
+	r, err := t.next.GetReportById(id)
+	endSpan(span, err)
+	return r, err
+}
+
+func (t *reportRepository) GetReportsByStatus(status report.Status) ([]*report.Report, error) {
+	_, span := startSpan(t.ctx, "ReportRepository.GetReportsByStatus", attribute.String("report.status", string(status)))
+	reports, err := t.next.GetReportsByStatus(status)
+	endSpan(span, err)
+	return reports, err
+}
+
+func (t *reportRepository) ClaimReport(id, moderatorId uuid.UUID) (*report.Report, error) {
+	_, span := startSpan(t.ctx, "ReportRepository.ClaimReport", attribute.String("report.id", id.String()))
+	r, err := t.next.ClaimReport(id, moderatorId)
+	endSpan(span, err)
+	return r, err
+}
+
+func (t *reportRepository) ResolveReport(id, moderatorId uuid.UUID, action report.Action, note string, apply report.Apply) ([]*report.Report, error) {
+	_, span := startSpan(t.ctx, "ReportRepository.ResolveReport",
+		attribute.String("report.id", id.String()), attribute.String("report.action", string(action)))
+	reports, err := t.next.ResolveReport(id, moderatorId, action, note, apply)
+	endSpan(span, err)
+	return reports, err
+}
+
+type auditLog struct {
+	ctx  context.Context
+	next audit.AuditLog
+}
+
+func AuditLog(ctx context.Context, next audit.AuditLog) audit.AuditLog {
+	return &auditLog{ctx: ctx, next: next}
+}
+
+func (t *auditLog) Record(entry audit.Entry) (*audit.Entry, error) {
+	_, span := startSpan(t.ctx, "AuditLog.Record", attribute.String("audit.action", entry.Action))
+	e, err := t.next.Record(entry)
+	endSpan(span, err)
+	return e, err
+}
+
+func (t *auditLog) GetEntries(limit int) ([]*audit.Entry, error) {
+	_, span := startSpan(t.ctx, "AuditLog.GetEntries")
+	entries, err := t.next.GetEntries(limit)
+	endSpan(span, err)
+	return entries, err
+}
+
+type relationRepository struct {
+	ctx  context.Context
+	next relation.RelationRepository
+}
+
+func Relations(ctx context.Context, next relation.RelationRepository) relation.RelationRepository {
+	return &relationRepository{ctx: ctx, next: next}
+}
+
+func (t *relationRepository) Block(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.Block", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.Block(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) Unblock(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.Unblock", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.Unblock(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) MuteUser(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.MuteUser", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.MuteUser(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) UnmuteUser(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.UnmuteUser", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.UnmuteUser(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) MuteKeyword(userId uuid.UUID, keyword string) error {
+	_, span := startSpan(t.ctx, "RelationRepository.MuteKeyword", attribute.String("user.id", userId.String()))
+	err := t.next.MuteKeyword(userId, keyword)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) UnmuteKeyword(userId uuid.UUID, keyword string) error {
+	_, span := startSpan(t.ctx, "RelationRepository.UnmuteKeyword", attribute.String("user.id", userId.String()))
+	err := t.next.UnmuteKeyword(userId, keyword)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) Follow(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.Follow", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.Follow(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) Unfollow(userId, targetId uuid.UUID) error {
+	_, span := startSpan(t.ctx, "RelationRepository.Unfollow", attribute.String("user.id", userId.String()), attribute.String("target.id", targetId.String()))
+	err := t.next.Unfollow(userId, targetId)
+	endSpan(span, err)
+	return err
+}
+
+func (t *relationRepository) GetRelations(userId uuid.UUID) (*relation.Relations, error) {
+	_, span := startSpan(t.ctx, "RelationRepository.GetRelations", attribute.String("user.id", userId.String()))
+	relations, err := t.next.GetRelations(userId)
+	endSpan(span, err)
+	return relations, err
+}
+
+type revisionRepository struct {
+	ctx  context.Context
+	next revision.RevisionRepository
+}
+
+func Revisions(ctx context.Context, next revision.RevisionRepository) revision.RevisionRepository {
+	return &revisionRepository{ctx: ctx, next: next}
+}
+
+func (t *revisionRepository) AddRevision(rev revision.Revision) (*revision.Revision, error) {
+	_, span := startSpan(t.ctx, "RevisionRepository.AddRevision", attribute.String("article.id", rev.ArticleId.String()))
+	r, err := t.next.AddRevision(rev)
+	endSpan(span, err)
+	return r, err
+}
+
+func (t *revisionRepository) GetRevisions(articleId uuid.UUID) ([]*revision.Revision, error) {
+	_, span := startSpan(t.ctx, "RevisionRepository.GetRevisions", attribute.String("article.id", articleId.String()))
+	revisions, err := t.next.GetRevisions(articleId)
+	endSpan(span, err)
+	return revisions, err
+}
+
+func (t *revisionRepository) GetRevision(articleId uuid.UUID, number int) (*revision.Revision, error) {
+	_, span := startSpan(t.ctx, "RevisionRepository.GetRevision",
+		attribute.String("article.id", articleId.String()), attribute.Int("revision.number", number))
+	r, err := t.next.GetRevision(articleId, number)
+	endSpan(span, err)
+	return r, err
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/session"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupTest(t *testing.T, sampleRatio float64) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider("test", sampleRatio, sdktrace.WithSyncer(exporter))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
+
+func spanByName(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func TestSpanTree(t *testing.T) {
+	exporter := setupTest(t, 1)
+
+	sessions := session.NewInMemorySession()
+	users := user.NewInMemoryUser()
+	handler := Instrument("/me", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := Sessions(r.Context(), sessions).CreateSession()
+		_, _ = Sessions(r.Context(), sessions).GetSessionById(s.SessionId)
+		_, _ = Users(r.Context(), users).GetUserById(uuid.New())
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/me?x=1", nil))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 4)
+
+	root := spanByName(spans, "GET /me")
+	if assert.NotNil(t, root) {
+		assert.False(t, root.Parent.IsValid(), "server span must be a root span")
+	}
+
+	for _, name := range []string{
+		"SessionRepository.CreateSession",
+		"SessionRepository.GetSessionById",
+		"UserRepository.GetUserById",
+	} {
+		child := spanByName(spans, name)
+		if assert.NotNil(t, child, name) {
+			assert.Equal(t, root.SpanContext.SpanID(), child.Parent.SpanID(), "%s must be a child of the request span", name)
+			assert.Equal(t, root.SpanContext.TraceID(), child.SpanContext.TraceID())
+		}
+	}
+
+	failed := spanByName(spans, "UserRepository.GetUserById")
+	assert.Equal(t, codes.Error, failed.Status.Code)
+	assert.Equal(t, "user not found", failed.Status.Description)
+}
+
+func TestSessionSpansOmitSessionID(t *testing.T) {
+	exporter := setupTest(t, 1)
+
+	sessions := session.NewInMemorySession()
+	s, _ := sessions.CreateSession()
+	userID := uuid.New()
+	_, _ = sessions.SetSessionUserId(s.SessionId, userID)
+
+	_, _ = Sessions(context.Background(), sessions).GetSessionById(s.SessionId)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		for _, attr := range spans[0].Attributes {
+			assert.NotContains(t, attr.Value.Emit(), s.SessionId.String(), string(attr.Key))
+		}
+		assert.Contains(t, spans[0].Attributes, attribute.String("user.id", userID.String()))
+	}
+}
+
+func TestPropagationAndSampling(t *testing.T) {
+	tests := []struct {
+		name        string
+		sampleRatio float64
+		traceparent string
+		wantSpans   int
+		wantTraceID string
+	}{
+		{
+			name:        "continues incoming trace",
+			sampleRatio: 1,
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantSpans:   1,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "sample ratio 0 drops new traces",
+			sampleRatio: 0,
+			wantSpans:   0,
+		},
+		{
+			name:        "sampled parent wins over ratio",
+			sampleRatio: 0,
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantSpans:   1,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupTest(t, tt.sampleRatio)
+			handler := Instrument("/feed", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, tt.wantSpans)
+			if tt.wantTraceID != "" && len(spans) > 0 {
+				assert.Equal(t, tt.wantTraceID, spans[0].SpanContext.TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package visibility decides which articles a viewer may see. Handlers build
+// one Filter per request so the lookups it needs are done once, not per
+// article; the same Filter is meant for every list of content a handler
+// returns, so feeds and future listings filter alike.
+package visibility
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/wordbreak"
+	"github.com/google/uuid"
+)
+
+type Filter struct {
+	viewer *auth.Principal
+	// hiddenAuthors are banned or shadow-banned; only they see their own articles.
+	hiddenAuthors map[uuid.UUID]struct{}
+	// blocked holds the users the viewer blocked and those who blocked the
+	// viewer: a block hides content both ways. Set by Personalize with the
+	// viewer's mutes.
+	blocked      map[uuid.UUID]struct{}
+	mutedAuthors map[uuid.UUID]struct{}
+	// mutedKeywords are split into words: a keyword matches whole words,
+	// several words match only in sequence.
+	mutedKeywords [][]string
+	// following opens the followers-only articles of these authors.
+	following map[uuid.UUID]struct{}
+}
+
+func NewFilter(viewer *auth.Principal, users user.UserRepository) (*Filter, error) {
+	ids, err := users.GetRestrictedUserIds(user.RestrictionBan, user.RestrictionShadowBan)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{viewer: viewer, hiddenAuthors: toSet(ids)}, nil
+}
+
+// Personalize applies the viewer's blocks, mutes and follows. It does
+// nothing for anonymous viewers.
+func (f *Filter) Personalize(relations relation.RelationRepository) error {
+	if !f.viewer.Authenticated() {
+		return nil
+	}
+	rel, err := relations.GetRelations(f.viewer.User.Id)
+	if err != nil {
+		return err
+	}
+	f.blocked = toSet(append(rel.Blocked, rel.BlockedBy...))
+	f.mutedAuthors = toSet(rel.MutedUsers)
+	f.following = toSet(rel.Following)
+	f.mutedKeywords = make([][]string, 0, len(rel.MutedKeywords))
+	for _, keyword := range rel.MutedKeywords {
+		if words := wordbreak.Words(strings.ToLower(keyword)); len(words) > 0 {
+			f.mutedKeywords = append(f.mutedKeywords, words)
+		}
+	}
+	return nil
+}
+
+// CanSee decides whether the viewer may open the article. Moderators can
+// open anything taken down by moderation so they can review their
+// decisions, but not other people's drafts or private articles.
+func (f *Filter) CanSee(a *article.Article) bool {
+	return (f.viewer.Can(rbac.ArticleHideAny) || f.allowed(a)) && f.reachable(a)
+}
+
+// Apply keeps the articles that belong in the viewer's feed: published
+// public ones and followers-only ones of followed authors, the viewer's own
+// included. Moderators get the same feed as
+// everyone else. Unlike blocks, mutes only trim lists: a muted article is
+// still reachable directly.
+func (f *Filter) Apply(articles []*article.Article) []*article.Article {
+	visible := make([]*article.Article, 0, len(articles))
+	for _, a := range articles {
+		if f.listed(a) && !f.muted(a) {
+			visible = append(visible, a)
+		}
+	}
+	return visible
+}
+
+func (f *Filter) listed(a *article.Article) bool {
+	if !f.allowed(a) || a.Status != article.StatusPublished {
+		return false
+	}
+	return a.Visibility == article.VisibilityPublic || (a.Visibility == article.VisibilityFollowers && f.follower(a))
+}
+
+// reachable applies the article's status and visibility: authors reach all
+// of their articles, everyone else only published or archived ones that are
+// public or unlisted, or followers-only ones of authors they follow.
+func (f *Filter) reachable(a *article.Article) bool {
+	if f.own(a) {
+		return true
+	}
+	if a.Status != article.StatusPublished && a.Status != article.StatusArchived {
+		return false
+	}
+	switch a.Visibility {
+	case article.VisibilityPublic, article.VisibilityUnlisted:
+		return true
+	case article.VisibilityFollowers:
+		return f.follower(a)
+	}
+	return false
+}
+
+// follower reports whether the viewer is in the audience of the author's
+// followers-only articles, which includes the author.
+func (f *Filter) follower(a *article.Article) bool {
+	if f.own(a) {
+		return true
+	}
+	_, ok := f.following[a.AuthorId]
+	return ok
+}
+
+// allowed applies moderation and the viewer's blocks.
+func (f *Filter) allowed(a *article.Article) bool {
+	if a.Hidden {
+		return false
+	}
+	if _, ok := f.hiddenAuthors[a.AuthorId]; ok {
+		return f.own(a)
+	}
+	if _, ok := f.blocked[a.AuthorId]; ok {
+		return false
+	}
+	return true
+}
+
+func (f *Filter) muted(a *article.Article) bool {
+	if f.own(a) {
+		return false
+	}
+	if _, ok := f.mutedAuthors[a.AuthorId]; ok {
+		return true
+	}
+	if len(f.mutedKeywords) == 0 {
+		return false
+	}
+	// the plain text, not Content: markup and block JSON keys aren't words
+	for _, text := range []string{a.Title, a.PlainText} {
+		words := wordbreak.Words(strings.ToLower(text))
+		for _, keyword := range f.mutedKeywords {
+			if containsSequence(words, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsSequence reports whether seq occurs in words as consecutive words.
+func containsSequence(words, seq []string) bool {
+	for i := 0; i+len(seq) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(seq)], seq) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) own(a *article.Article) bool {
+	return f.viewer.Authenticated() && f.viewer.User.Id == a.AuthorId
+}
+
+func toSet(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
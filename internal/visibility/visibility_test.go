@@ -0,0 +1,253 @@
+package visibility
+
+import (
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/auth"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/rbac"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/article"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/relation"
+	"github.com/go-park-mail-ru/2025_2_MindLeak/internal/repository/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	users := user.NewInMemoryUser()
+	shadowBanned, _ := users.CreateUser("shadow@mail.ru", "password1", "Shadow")
+	_, _ = users.Restrict(shadowBanned.Id, user.Restriction{Kind: user.RestrictionShadowBan})
+	banned, _ := users.CreateUser("banned@mail.ru", "password1", "Banned")
+	_, _ = users.Restrict(banned.Id, user.Restriction{Kind: user.RestrictionBan})
+	suspended, _ := users.CreateUser("suspended@mail.ru", "password1", "Suspended")
+	_, _ = users.Restrict(suspended.Id, user.Restriction{Kind: user.RestrictionSuspension})
+	reader, _ := users.CreateUser("reader@mail.ru", "password1", "Reader")
+
+	regular := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: reader.Id}
+	hidden := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: reader.Id, Hidden: true}
+	shadowed := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: shadowBanned.Id}
+	ofBanned := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: banned.Id}
+	ofSuspended := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: suspended.Id}
+	all := []*article.Article{regular, hidden, shadowed, ofBanned, ofSuspended}
+
+	moderator := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleModerator}}
+
+	tests := []struct {
+		name     string
+		viewer   *auth.Principal
+		wantFeed []*article.Article
+		wantSee  []*article.Article
+	}{
+		{
+			name:     "anonymous",
+			wantFeed: []*article.Article{regular, ofSuspended},
+			wantSee:  []*article.Article{regular, ofSuspended},
+		},
+		{
+			name:     "shadow-banned author",
+			viewer:   &auth.Principal{User: shadowBanned},
+			wantFeed: []*article.Article{regular, shadowed, ofSuspended},
+			wantSee:  []*article.Article{regular, shadowed, ofSuspended},
+		},
+		{
+			name:     "moderator",
+			viewer:   &auth.Principal{User: moderator},
+			wantFeed: []*article.Article{regular, ofSuspended},
+			wantSee:  all,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.viewer, users)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantFeed, filter.Apply(all))
+			var seen []*article.Article
+			for _, a := range all {
+				if filter.CanSee(a) {
+					seen = append(seen, a)
+				}
+			}
+			assert.Equal(t, tt.wantSee, seen)
+		})
+	}
+}
+
+func TestFilterPersonalize(t *testing.T) {
+	users := user.NewInMemoryUser()
+	viewer, _ := users.CreateUser("viewer@mail.ru", "password1", "Viewer")
+	blocked, _ := users.CreateUser("blocked@mail.ru", "password1", "Blocked")
+	muted, _ := users.CreateUser("muted@mail.ru", "password1", "Muted")
+	other, _ := users.CreateUser("other@mail.ru", "password1", "Other")
+	blocker, _ := users.CreateUser("blocker@mail.ru", "password1", "Blocker")
+
+	relations := relation.NewInMemoryRelation()
+	_ = relations.Block(viewer.Id, blocked.Id)
+	_ = relations.Block(blocker.Id, viewer.Id)
+	_ = relations.MuteUser(viewer.Id, muted.Id)
+	_ = relations.MuteKeyword(viewer.Id, "крипта")
+	_ = relations.MuteKeyword(viewer.Id, "paragraph")
+
+	ofBlocked := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: blocked.Id, Title: "Новости"}
+	ofMuted := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: muted.Id, Title: "Новости"}
+	withKeyword := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: other.Id, Title: "Почему КРИПТА растёт"}
+	own := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: viewer.Id, PlainText: "Моя крипта"}
+	regular := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: other.Id, Title: "Новости"}
+	withKeywordInText := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: other.Id, Title: "Курсы", PlainText: "Курс: крипта падает"}
+	blocks := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: other.Id, Title: "Блоки",
+		Format: article.FormatBlocks, Content: `{"version":1,"blocks":[{"type":"paragraph","data":{"text":"Текст"}}]}`, PlainText: "Текст"}
+	ofBlocker := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: blocker.Id, Title: "Новости"}
+	all := []*article.Article{ofBlocked, ofMuted, withKeyword, own, regular, withKeywordInText, blocks, ofBlocker}
+
+	tests := []struct {
+		name     string
+		viewer   *auth.Principal
+		wantFeed []*article.Article
+		wantSee  []*article.Article
+	}{
+		{
+			name:     "viewer with blocks and mutes",
+			viewer:   &auth.Principal{User: viewer},
+			wantFeed: []*article.Article{own, regular, blocks},
+			wantSee:  []*article.Article{ofMuted, withKeyword, own, regular, withKeywordInText, blocks},
+		},
+		{
+			name:     "anonymous is unaffected",
+			wantFeed: all,
+			wantSee:  all,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.viewer, users)
+			require.NoError(t, err)
+			require.NoError(t, filter.Personalize(relations))
+
+			assert.Equal(t, tt.wantFeed, filter.Apply(all))
+			var seen []*article.Article
+			for _, a := range all {
+				if filter.CanSee(a) {
+					seen = append(seen, a)
+				}
+			}
+			assert.Equal(t, tt.wantSee, seen)
+		})
+	}
+}
+
+func TestFilterMutedKeywords(t *testing.T) {
+	users := user.NewInMemoryUser()
+	viewer, _ := users.CreateUser("viewer@mail.ru", "password1", "Viewer")
+	other, _ := users.CreateUser("other@mail.ru", "password1", "Other")
+
+	relations := relation.NewInMemoryRelation()
+	_ = relations.MuteKeyword(viewer.Id, "ai")
+	_ = relations.MuteKeyword(viewer.Id, "кот")
+	_ = relations.MuteKeyword(viewer.Id, "Машинное обучение")
+
+	tests := []struct {
+		name      string
+		title     string
+		text      string
+		wantMuted bool
+	}{
+		{name: "whole word", title: "AI в медицине", wantMuted: true},
+		{name: "inside an english word", text: "He said so", wantMuted: false},
+		{name: "russian word", text: "Мой кот спит.", wantMuted: true},
+		{name: "inside a russian word", title: "Который час", text: "Котлеты и котята", wantMuted: false},
+		{name: "phrase", text: "Основы: машинное обучение для всех", wantMuted: true},
+		{name: "phrase across punctuation", text: "Машинное, обучение", wantMuted: true},
+		{name: "phrase words apart", text: "Машинное и глубокое обучение", wantMuted: false},
+		{name: "phrase across title and text", title: "Машинное", text: "обучение", wantMuted: false},
+	}
+
+	filter, err := NewFilter(&auth.Principal{User: viewer}, users)
+	require.NoError(t, err)
+	require.NoError(t, filter.Personalize(relations))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &article.Article{Id: uuid.New(), Status: article.StatusPublished, Visibility: article.VisibilityPublic, AuthorId: other.Id,
+				Title: tt.title, PlainText: tt.text}
+			assert.Equal(t, tt.wantMuted, len(filter.Apply([]*article.Article{a})) == 0)
+		})
+	}
+}
+
+func TestFilterLifecycle(t *testing.T) {
+	users := user.NewInMemoryUser()
+	author, _ := users.CreateUser("author@mail.ru", "password1", "Author")
+	reader, _ := users.CreateUser("reader@mail.ru", "password1", "Reader")
+	follower, _ := users.CreateUser("follower@mail.ru", "password1", "Follower")
+	moderator := &user.User{Id: uuid.New(), Roles: []rbac.Role{rbac.RoleModerator}}
+
+	newArticle := func(status article.Status, visibility article.Visibility) *article.Article {
+		return &article.Article{Id: uuid.New(), AuthorId: author.Id, Status: status, Visibility: visibility}
+	}
+	draft := newArticle(article.StatusDraft, article.VisibilityPublic)
+	scheduled := newArticle(article.StatusScheduled, article.VisibilityPublic)
+	published := newArticle(article.StatusPublished, article.VisibilityPublic)
+	archived := newArticle(article.StatusArchived, article.VisibilityPublic)
+	unlisted := newArticle(article.StatusPublished, article.VisibilityUnlisted)
+	forFollowers := newArticle(article.StatusPublished, article.VisibilityFollowers)
+	private := newArticle(article.StatusPublished, article.VisibilityPrivate)
+	all := []*article.Article{draft, scheduled, published, archived, unlisted, forFollowers, private}
+
+	relations := relation.NewInMemoryRelation()
+	_ = relations.Follow(follower.Id, author.Id)
+
+	tests := []struct {
+		name     string
+		viewer   *auth.Principal
+		wantFeed []*article.Article
+		wantSee  []*article.Article
+	}{
+		{
+			name:     "anonymous",
+			wantFeed: []*article.Article{published},
+			wantSee:  []*article.Article{published, archived, unlisted},
+		},
+		{
+			name:     "reader",
+			viewer:   &auth.Principal{User: reader},
+			wantFeed: []*article.Article{published},
+			wantSee:  []*article.Article{published, archived, unlisted},
+		},
+		{
+			name:     "follower",
+			viewer:   &auth.Principal{User: follower},
+			wantFeed: []*article.Article{published, forFollowers},
+			wantSee:  []*article.Article{published, archived, unlisted, forFollowers},
+		},
+		{
+			name:     "moderator",
+			viewer:   &auth.Principal{User: moderator},
+			wantFeed: []*article.Article{published},
+			wantSee:  []*article.Article{published, archived, unlisted},
+		},
+		{
+			name:     "author",
+			viewer:   &auth.Principal{User: author},
+			wantFeed: []*article.Article{published, forFollowers},
+			wantSee:  all,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.viewer, users)
+			require.NoError(t, err)
+			require.NoError(t, filter.Personalize(relations))
+
+			assert.Equal(t, tt.wantFeed, filter.Apply(all))
+			var seen []*article.Article
+			for _, a := range all {
+				if filter.CanSee(a) {
+					seen = append(seen, a)
+				}
+			}
+			assert.Equal(t, tt.wantSee, seen)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{CodeBadRequest, http.StatusBadRequest},
+		{CodeValidation, http.StatusBadRequest},
+		{CodeUnauthorized, http.StatusUnauthorized},
+		{CodeNotFound, http.StatusNotFound},
+		{CodePayloadTooLarge, http.StatusRequestEntityTooLarge},
+		{Code("unknown"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			assert.Equal(t, tt.want, StatusFor(tt.code))
+		})
+	}
+}
+
+func TestCodeFor(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Code
+	}{
+		{http.StatusBadRequest, CodeBadRequest},
+		{http.StatusUnauthorized, CodeUnauthorized},
+		{http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{http.StatusTooManyRequests, CodeTooManyRequests},
+		{http.StatusBadGateway, CodeInternal},
+		{http.StatusTeapot, CodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeFor(tt.status))
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	notFound := New(CodeNotFound, "article not found")
+	assert.Same(t, notFound, From(fmt.Errorf("lookup: %w", notFound)))
+
+	cause := errors.New("db is on fire")
+	internal := From(cause)
+	assert.Equal(t, CodeInternal, internal.Code)
+	assert.Equal(t, "internal server error", internal.Message)
+	assert.ErrorIs(t, internal, cause)
+}
+
+func TestValidation(t *testing.T) {
+	err := Validation("email", "invalid email")
+	assert.Equal(t, http.StatusBadRequest, err.Status())
+	assert.Equal(t, []FieldError{{Field: "email", Message: "invalid email"}}, err.Fields)
+	assert.Equal(t, "invalid email", err.Error())
+}
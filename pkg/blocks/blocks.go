@@ -0,0 +1,181 @@
+// Package blocks implements the block document an Editor.js-style editor
+// produces: a versioned list of paragraphs, headers, images, quotes, lists,
+// embeds and code.
+//
+// Documents are checked against an embedded JSON schema that also carries
+// the per-block limits. Text in paragraphs, headers, quotes, list items and
+// captions may hold the inline HTML the editor emits (bold, italic, links
+// and so on); everything else in it is stripped when the document is
+// rendered.
+package blocks
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// CurrentVersion is the version new documents are written in.
+const CurrentVersion = 1
+
+const (
+	TypeParagraph = "paragraph"
+	TypeHeader    = "header"
+	TypeImage     = "image"
+	TypeQuote     = "quote"
+	TypeList      = "list"
+	TypeEmbed     = "embed"
+	TypeCode      = "code"
+)
+
+var (
+	ErrInvalidDocument    = errors.New("invalid block document")
+	ErrUnsupportedVersion = errors.New("unsupported block document version")
+)
+
+type Document struct {
+	Version int     `json:"version"`
+	Blocks  []Block `json:"blocks"`
+}
+
+type Block struct {
+	// Id is assigned by the editor and kept as is.
+	Id   string          `json:"id,omitempty"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Paragraph struct {
+	Text string `json:"text"`
+}
+
+type Header struct {
+	Text  string `json:"text"`
+	Level int    `json:"level"`
+}
+
+type Image struct {
+	File struct {
+		URL string `json:"url"`
+	} `json:"file"`
+	Caption        string `json:"caption,omitempty"`
+	WithBorder     bool   `json:"withBorder,omitempty"`
+	Stretched      bool   `json:"stretched,omitempty"`
+	WithBackground bool   `json:"withBackground,omitempty"`
+}
+
+type Quote struct {
+	Text      string `json:"text"`
+	Caption   string `json:"caption,omitempty"`
+	Alignment string `json:"alignment,omitempty"`
+}
+
+type List struct {
+	Style string   `json:"style"`
+	Items []string `json:"items"`
+}
+
+type Embed struct {
+	Service string `json:"service"`
+	Source  string `json:"source"`
+	Embed   string `json:"embed"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Caption string `json:"caption,omitempty"`
+}
+
+type Code struct {
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+}
+
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = mustCompile()
+
+func mustCompile() *jsonschema.Schema {
+	raw, err := jsonschema.UnmarshalJSON(bytes.NewReader(schemaJSON))
+	if err != nil {
+		panic(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("blocks.json", raw); err != nil {
+		panic(err)
+	}
+	return compiler.MustCompile("blocks.json")
+}
+
+var printer = message.NewPrinter(language.English)
+
+// Parse validates a document and returns it. Errors wrap ErrInvalidDocument
+// or ErrUnsupportedVersion and name the offending value.
+func Parse(data []byte) (*Document, error) {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		var verr *jsonschema.ValidationError
+		if errors.As(err, &verr) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, describe(verr))
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	doc := new(Document)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if doc.Version > CurrentVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, doc.Version)
+	}
+	for i, block := range doc.Blocks {
+		if block.Type != TypeEmbed {
+			continue
+		}
+		var embed Embed
+		_ = json.Unmarshal(block.Data, &embed)
+		if !embeddable(embed.Service, embed.Embed) {
+			return nil, fmt.Errorf("%w: at /blocks/%d/data/embed: not a %s player URL", ErrInvalidDocument, i, embed.Service)
+		}
+	}
+	return doc, nil
+}
+
+// describe reduces a validation error to its first leaf, which is the one
+// that names the actual problem.
+func describe(err *jsonschema.ValidationError) string {
+	for len(err.Causes) > 0 {
+		err = err.Causes[0]
+	}
+	return fmt.Sprintf("at /%s: %s", strings.Join(err.InstanceLocation, "/"), err.ErrorKind.LocalizedString(printer))
+}
+
+// Marshal returns the compact JSON of the document, which is how it is stored.
+func (d *Document) Marshal() string {
+	return string(encode(d))
+}
+
+// Append adds a block, encoding its data.
+func (d *Document) Append(blockType string, data any) {
+	d.Blocks = append(d.Blocks, Block{Type: blockType, Data: encode(data)})
+}
+
+// encode leaves < and > in block text as they are, so stored documents
+// stay readable and revision diffs show the markup the author wrote.
+func encode(v any) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// the document types always encode
+	_ = enc.Encode(v)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
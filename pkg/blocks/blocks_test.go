@@ -0,0 +1,146 @@
+package blocks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name: "every block type",
+			doc: `{"version":1,"blocks":[
+				{"id":"a1","type":"header","data":{"text":"Итоги","level":2}},
+				{"type":"paragraph","data":{"text":"Рост на <b>30%</b>"}},
+				{"type":"image","data":{"file":{"url":"https://example.com/a.png"},"caption":"График","stretched":true}},
+				{"type":"quote","data":{"text":"Цитата","caption":"Автор","alignment":"left"}},
+				{"type":"list","data":{"style":"ordered","items":["один","два"]}},
+				{"type":"embed","data":{"service":"youtube","source":"https://youtu.be/x","embed":"https://www.youtube.com/embed/x","width":580,"height":320}},
+				{"type":"code","data":{"code":"fmt.Println()","language":"go"}}
+			]}`,
+		},
+		{name: "not json", doc: `{"version":`, wantErr: "invalid block document: "},
+		{name: "no blocks", doc: `{"version":1,"blocks":[]}`, wantErr: "invalid block document: at /blocks: "},
+		{name: "unknown block type", doc: `{"version":1,"blocks":[{"type":"table","data":{}}]}`, wantErr: "invalid block document: at /blocks/0/type: "},
+		{name: "paragraph too long", doc: `{"version":1,"blocks":[{"type":"paragraph","data":{"text":"` + strings.Repeat("я", 10001) + `"}}]}`, wantErr: "invalid block document: at /blocks/0/data/text: "},
+		{name: "header level", doc: `{"version":1,"blocks":[{"type":"header","data":{"text":"Итоги","level":7}}]}`, wantErr: "invalid block document: at /blocks/0/data/level: "},
+		{name: "image without http url", doc: `{"version":1,"blocks":[{"type":"image","data":{"file":{"url":"javascript:alert(1)"}}}]}`, wantErr: "invalid block document: at /blocks/0/data/file/url: "},
+		{name: "unknown data field", doc: `{"version":1,"blocks":[{"type":"paragraph","data":{"text":"a","style":"x"}}]}`, wantErr: "invalid block document: at /blocks/0/data: "},
+		{
+			name:    "embed from another host",
+			doc:     `{"version":1,"blocks":[{"type":"embed","data":{"service":"youtube","source":"https://evil.example","embed":"https://evil.example/embed/x"}}]}`,
+			wantErr: "invalid block document: at /blocks/0/data/embed: not a youtube player URL",
+		},
+		{name: "newer version", doc: `{"version":2,"blocks":[{"type":"paragraph","data":{"text":"a"}}]}`, wantErr: "unsupported block document version: 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.doc))
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.True(t, strings.HasPrefix(err.Error(), tt.wantErr), err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, doc.Blocks, 7)
+		})
+	}
+}
+
+func TestDocumentHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   Block
+		want    string
+		notWant []string
+	}{
+		{
+			name:  "paragraph keeps inline markup",
+			block: Block{Type: TypeParagraph, Data: []byte(`{"text":"Рост на <b>30%</b> <a href=\"https://example.com\">здесь</a>"}`)},
+			want:  `<p>Рост на <b>30%</b> <a href="https://example.com" rel="nofollow">здесь</a></p>`,
+		},
+		{
+			name:    "paragraph loses scripts",
+			block:   Block{Type: TypeParagraph, Data: []byte(`{"text":"<script>alert(1)</script><img src=x onerror=alert(1)>текст"}`)},
+			want:    "<p>текст</p>",
+			notWant: []string{"script", "onerror"},
+		},
+		{
+			name:  "header",
+			block: Block{Type: TypeHeader, Data: []byte(`{"text":"Итоги","level":3}`)},
+			want:  "<h3>Итоги</h3>",
+		},
+		{
+			name:  "image",
+			block: Block{Type: TypeImage, Data: []byte(`{"file":{"url":"https://example.com/a.png?x=1&y=2"},"caption":"<i>График</i>","withBorder":true}`)},
+			want:  `<figure class="image image--bordered"><img src="https://example.com/a.png?x=1&amp;y=2" alt="График"><figcaption><i>График</i></figcaption></figure>`,
+		},
+		{
+			name:  "quote",
+			block: Block{Type: TypeQuote, Data: []byte(`{"text":"Цитата","caption":"Автор"}`)},
+			want:  "<blockquote><p>Цитата</p><cite>Автор</cite></blockquote>",
+		},
+		{
+			name:  "list",
+			block: Block{Type: TypeList, Data: []byte(`{"style":"ordered","items":["один","<b>два</b>"]}`)},
+			want:  "<ol><li>один</li><li><b>два</b></li></ol>",
+		},
+		{
+			name:  "embed",
+			block: Block{Type: TypeEmbed, Data: []byte(`{"service":"vimeo","source":"https://vimeo.com/1","embed":"https://player.vimeo.com/video/1","width":640,"height":360}`)},
+			want:  `<figure class="embed"><iframe src="https://player.vimeo.com/video/1" width="640" height="360" frameborder="0" allowfullscreen></iframe></figure>`,
+		},
+		{
+			name:    "embed from another host is skipped",
+			block:   Block{Type: TypeEmbed, Data: []byte(`{"service":"vimeo","source":"https://evil.example","embed":"https://evil.example/1"}`)},
+			notWant: []string{"iframe"},
+		},
+		{
+			name:    "highlighted code",
+			block:   Block{Type: TypeCode, Data: []byte(`{"code":"func main() {}","language":"go"}`)},
+			want:    `<span class="kd">func</span>`,
+			notWant: []string{"style="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Document{Version: CurrentVersion, Blocks: []Block{tt.block}}).HTML()
+			assert.Contains(t, got, tt.want)
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, got, notWant)
+			}
+		})
+	}
+}
+
+func TestDocumentPlainText(t *testing.T) {
+	doc, err := Parse([]byte(`{"version":1,"blocks":[
+		{"type":"header","data":{"text":"Итоги","level":2}},
+		{"type":"paragraph","data":{"text":"Рост на&nbsp;<b>30%</b>,<br>а&nbsp;убийца — <span class=\"spoiler\">дворецкий</span>"}},
+		{"type":"code","data":{"code":"fmt.Println()"}},
+		{"type":"image","data":{"file":{"url":"https://example.com/a.png"},"caption":"График"}},
+		{"type":"list","data":{"style":"unordered","items":["один","два"]}}
+	]}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Итоги\nРост на 30%, а убийца — …\nодин\nдва", doc.PlainText())
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	doc := &Document{Version: CurrentVersion}
+	doc.Append(TypeParagraph, Paragraph{Text: "Текст"})
+	doc.Append(TypeList, List{Style: "unordered", Items: []string{"один"}})
+
+	parsed, err := Parse([]byte(doc.Marshal()))
+	assert.NoError(t, err)
+	assert.Equal(t, doc.Marshal(), parsed.Marshal())
+}
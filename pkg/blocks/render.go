@@ -0,0 +1,214 @@
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// inline is what the editor's inline tools produce, plus spoilers carried
+// over from Markdown.
+var inline = newInlinePolicy()
+
+func newInlinePolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("b", "strong", "i", "em", "u", "s", "mark", "code", "br")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^spoiler$`)).OnElements("span")
+	p.AllowStandardURLs()
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+var plain = bluemonday.StrictPolicy()
+
+// SanitizeInline strips everything block text may not hold.
+func SanitizeInline(fragment string) string {
+	return inline.Sanitize(fragment)
+}
+
+// players are the URL prefixes an embed of each service may load.
+var players = map[string][]string{
+	"youtube": {"https://www.youtube.com/embed/", "https://www.youtube-nocookie.com/embed/"},
+	"vimeo":   {"https://player.vimeo.com/video/"},
+	"coub":    {"https://coub.com/embed/"},
+	"rutube":  {"https://rutube.ru/play/embed/"},
+	"vk":      {"https://vk.com/video_ext.php?", "https://vkvideo.ru/video_ext.php?"},
+}
+
+func embeddable(service, player string) bool {
+	if _, err := url.ParseRequestURI(player); err != nil {
+		return false
+	}
+	for _, prefix := range players[service] {
+		if strings.HasPrefix(player, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var codeFormatter = chromahtml.New(chromahtml.WithClasses(true))
+
+// HTML renders the document. Block text is sanitized on the way, so the
+// result is safe to embed even if the document skipped Parse.
+func (d *Document) HTML() string {
+	var b strings.Builder
+	for _, block := range d.Blocks {
+		switch block.Type {
+		case TypeParagraph:
+			var p Paragraph
+			_ = json.Unmarshal(block.Data, &p)
+			fmt.Fprintf(&b, "<p>%s</p>\n", inline.Sanitize(p.Text))
+		case TypeHeader:
+			var h Header
+			_ = json.Unmarshal(block.Data, &h)
+			level := min(max(h.Level, 1), 6)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, inline.Sanitize(h.Text), level)
+		case TypeImage:
+			var img Image
+			_ = json.Unmarshal(block.Data, &img)
+			if !isHTTP(img.File.URL) {
+				continue
+			}
+			fmt.Fprintf(&b, `<figure class="image%s"><img src="%s" alt="%s">`, imageClasses(img),
+				html.EscapeString(img.File.URL), html.EscapeString(toText(img.Caption)))
+			writeCaption(&b, img.Caption)
+			b.WriteString("</figure>\n")
+		case TypeQuote:
+			var q Quote
+			_ = json.Unmarshal(block.Data, &q)
+			fmt.Fprintf(&b, "<blockquote><p>%s</p>", inline.Sanitize(q.Text))
+			if q.Caption != "" {
+				fmt.Fprintf(&b, "<cite>%s</cite>", inline.Sanitize(q.Caption))
+			}
+			b.WriteString("</blockquote>\n")
+		case TypeList:
+			var l List
+			_ = json.Unmarshal(block.Data, &l)
+			tag := "ul"
+			if l.Style == "ordered" {
+				tag = "ol"
+			}
+			fmt.Fprintf(&b, "<%s>", tag)
+			for _, item := range l.Items {
+				fmt.Fprintf(&b, "<li>%s</li>", inline.Sanitize(item))
+			}
+			fmt.Fprintf(&b, "</%s>\n", tag)
+		case TypeEmbed:
+			var e Embed
+			_ = json.Unmarshal(block.Data, &e)
+			if !embeddable(e.Service, e.Embed) {
+				continue
+			}
+			fmt.Fprintf(&b, `<figure class="embed"><iframe src="%s"`, html.EscapeString(e.Embed))
+			if e.Width > 0 && e.Height > 0 {
+				fmt.Fprintf(&b, ` width="%d" height="%d"`, e.Width, e.Height)
+			}
+			b.WriteString(` frameborder="0" allowfullscreen></iframe>`)
+			writeCaption(&b, e.Caption)
+			b.WriteString("</figure>\n")
+		case TypeCode:
+			var c Code
+			_ = json.Unmarshal(block.Data, &c)
+			writeCode(&b, c)
+		}
+	}
+	return b.String()
+}
+
+// PlainText returns the readable text of the document for search and
+// excerpts, one block per line. Code, images and embeds are left out.
+func (d *Document) PlainText() string {
+	lines := make([]string, 0, len(d.Blocks))
+	add := func(text string) {
+		if text = strings.Join(strings.Fields(toText(text)), " "); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	for _, block := range d.Blocks {
+		switch block.Type {
+		case TypeParagraph:
+			var p Paragraph
+			_ = json.Unmarshal(block.Data, &p)
+			add(p.Text)
+		case TypeHeader:
+			var h Header
+			_ = json.Unmarshal(block.Data, &h)
+			add(h.Text)
+		case TypeQuote:
+			var q Quote
+			_ = json.Unmarshal(block.Data, &q)
+			add(q.Text)
+		case TypeList:
+			var l List
+			_ = json.Unmarshal(block.Data, &l)
+			for _, item := range l.Items {
+				add(item)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var spoiler = regexp.MustCompile(`<span class="spoiler">.*?</span>`)
+
+// toText strips the markup of a text fragment. Spoilers turn into an
+// ellipsis, as they do in Markdown excerpts.
+func toText(fragment string) string {
+	fragment = spoiler.ReplaceAllString(inline.Sanitize(fragment), "…")
+	// <br> separates words just like a space does
+	fragment = strings.NewReplacer("<br>", " ", "<br/>", " ").Replace(fragment)
+	return html.UnescapeString(plain.Sanitize(fragment))
+}
+
+func writeCaption(b *strings.Builder, caption string) {
+	if caption != "" {
+		fmt.Fprintf(b, "<figcaption>%s</figcaption>", inline.Sanitize(caption))
+	}
+}
+
+func imageClasses(img Image) string {
+	var classes string
+	if img.WithBorder {
+		classes += " image--bordered"
+	}
+	if img.Stretched {
+		classes += " image--stretched"
+	}
+	if img.WithBackground {
+		classes += " image--background"
+	}
+	return classes
+}
+
+// writeCode highlights the code the same way Markdown code blocks are, with
+// chroma CSS classes; unknown languages stay plain.
+func writeCode(b *strings.Builder, c Code) {
+	lexer := lexers.Get(c.Language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, c.Code)
+	if err == nil {
+		err = codeFormatter.Format(b, styles.Fallback, iterator)
+	}
+	if err != nil {
+		fmt.Fprintf(b, "<pre><code>%s</code></pre>", html.EscapeString(c.Code))
+	}
+	b.WriteString("\n")
+}
+
+func isHTTP(link string) bool {
+	u, err := url.ParseRequestURI(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
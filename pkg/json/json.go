@@ -2,39 +2,125 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
 )
 
-func Read(r *http.Request, int interface{}) error {
-	body, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
-	if err != nil {
-		return err
+// MaxBodyBytes caps request bodies read with Read.
+const MaxBodyBytes = 1 << 20
+
+var (
+	ErrUnsupportedMediaType = apperror.New(apperror.CodeUnsupportedMedia, "content type must be application/json")
+	ErrBodyTooLarge         = apperror.New(apperror.CodePayloadTooLarge, "request body is too large")
+	ErrEmptyBody            = apperror.New(apperror.CodeBadRequest, "request body must not be empty")
+	ErrTrailingData         = apperror.New(apperror.CodeBadRequest, "request body must contain a single JSON value")
+)
+
+// Read decodes a JSON request body of at most MaxBodyBytes into dst.
+func Read(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return ReadLimit(w, r, dst, MaxBodyBytes)
+}
+
+// ReadLimit decodes exactly one JSON value into dst, rejecting unknown fields.
+// Errors are *apperror.Error so handlers can pass them to WriteAppError as is.
+func ReadLimit(w http.ResponseWriter, r *http.Request, dst interface{}, limit int64) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return ErrUnsupportedMediaType
+	}
+
+	body := http.MaxBytesReader(w, r.Body, limit)
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrBodyTooLarge
+		}
+		return ErrTrailingData
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrEmptyBody
+	case errors.As(err, &maxBytesErr):
+		return ErrBodyTooLarge
+	case errors.As(err, &syntaxErr):
+		return apperror.Wrap(apperror.CodeBadRequest,
+			fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset), err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.Wrap(apperror.CodeBadRequest, "malformed JSON", err)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return apperror.Wrap(apperror.CodeBadRequest, "request body has the wrong JSON type", err)
+		}
+		appErr := apperror.Validation(field, "must be of type "+typeErr.Type.String())
+		appErr.Err = err
+		return appErr
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for DisallowUnknownFields
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return apperror.Wrap(apperror.CodeBadRequest, "unknown field "+field, err)
 	}
-	return json.Unmarshal(body, &int)
+	return apperror.Wrap(apperror.CodeBadRequest, "invalid request body", err)
 }
 
-func Write(w http.ResponseWriter, status int, int interface{}) error {
-	resp, err := json.Marshal(int)
+func Write(w http.ResponseWriter, status int, v interface{}) error {
+	resp, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_, err = w.Write(resp)
-	if err != nil {
-		return err
-	}
-	//w.WriteHeader(status)
-	return nil
+	return err
+}
+
+// ErrorResponse is the envelope of every error response. Error stays a plain
+// string so older clients reading only it keep working.
+type ErrorResponse struct {
+	Error   string                `json:"error"`
+	Code    apperror.Code         `json:"code"`
+	Details []apperror.FieldError `json:"details,omitempty"`
 }
 
 func WriteError(w http.ResponseWriter, status int, msg string) {
+	writeErrorResponse(w, status, ErrorResponse{Error: msg, Code: apperror.CodeFor(status)})
+}
+
+func WriteAppError(w http.ResponseWriter, err error) {
+	appErr := apperror.From(err)
+	writeErrorResponse(w, appErr.Status(), ErrorResponse{
+		Error:   appErr.Message,
+		Code:    appErr.Code,
+		Details: appErr.Fields,
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, resp ErrorResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
-	resp := map[string]string{"error": msg}
 	data, _ := json.Marshal(resp)
 	w.Write(data)
 }
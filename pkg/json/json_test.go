@@ -0,0 +1,82 @@
+package json
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+type input struct {
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+func TestReadLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     error
+		wantStatus  int
+		wantField   string
+		want        input
+	}{
+		{name: "valid", contentType: "application/json", body: `{"email":"a@b.c","age":3}`, want: input{Email: "a@b.c", Age: 3}},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", body: `{"email":"a@b.c"}`, want: input{Email: "a@b.c"}},
+		{name: "missing content type", body: `{}`, wantErr: ErrUnsupportedMediaType, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "form content type", contentType: "application/x-www-form-urlencoded", body: `{}`, wantErr: ErrUnsupportedMediaType, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "empty body", contentType: "application/json", wantErr: ErrEmptyBody, wantStatus: http.StatusBadRequest},
+		{name: "too large", contentType: "application/json", body: `{"email":"` + strings.Repeat("a", 64) + `"}`, wantErr: ErrBodyTooLarge, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "trailing data", contentType: "application/json", body: `{"email":"a@b.c"}{}`, wantErr: ErrTrailingData, wantStatus: http.StatusBadRequest},
+		{name: "trailing garbage", contentType: "application/json", body: `{"email":"a@b.c"} x`, wantErr: ErrTrailingData, wantStatus: http.StatusBadRequest},
+		{name: "trailing whitespace", contentType: "application/json", body: "{\"age\":1}\n", want: input{Age: 1}},
+		{name: "unknown field", contentType: "application/json", body: `{"emial":"a@b.c"}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed", contentType: "application/json", body: `{"email":`, wantStatus: http.StatusBadRequest},
+		{name: "syntax error", contentType: "application/json", body: `{"email" "a"}`, wantStatus: http.StatusBadRequest},
+		{name: "wrong type", contentType: "application/json", body: `{"age":"old"}`, wantStatus: http.StatusBadRequest, wantField: "age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var got input
+			err := ReadLimit(httptest.NewRecorder(), req, &got, 32)
+
+			if tt.wantStatus == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+
+			var appErr *apperror.Error
+			if assert.True(t, errors.As(err, &appErr)) {
+				assert.Equal(t, tt.wantStatus, appErr.Status())
+			}
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantField != "" {
+				assert.Equal(t, apperror.CodeValidation, appErr.Code)
+				assert.Equal(t, tt.wantField, appErr.Fields[0].Field)
+			}
+		})
+	}
+}
+
+func TestWriteAppError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteAppError(w, apperror.Validation("email", "invalid email"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"invalid email","code":"validation_failed","details":[{"field":"email","message":"invalid email"}]}`, w.Body.String())
+}
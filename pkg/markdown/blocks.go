@@ -0,0 +1,160 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/blocks"
+	gast "github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var codeLanguage = regexp.MustCompile(`^[a-z0-9+#-]{1,32}$`)
+
+// ToBlocks converts Markdown, and with it plain text, to a block document.
+// Paragraphs, headings, quotes, lists, code and standalone images map to
+// their blocks; a table becomes a paragraph per row and footnotes an
+// ordered list at the end. Raw HTML and thematic breaks are dropped, just
+// as Render drops them.
+func ToBlocks(source string) *blocks.Document {
+	src := []byte(source)
+	root := converter.Parser().Parse(text.NewReader(src))
+	inlineFootnotes(root)
+
+	c := &blockConverter{source: src, doc: &blocks.Document{Version: blocks.CurrentVersion}}
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		c.convert(n)
+	}
+	if len(c.doc.Blocks) == 0 {
+		c.doc.Append(blocks.TypeParagraph, blocks.Paragraph{Text: html.EscapeString(strings.TrimSpace(source))})
+	}
+	return c.doc
+}
+
+// inlineFootnotes turns footnote references into plain [n] markers, since
+// block text has nowhere for them to point to.
+func inlineFootnotes(root gast.Node) {
+	var links, backlinks []gast.Node
+	_ = gast.Walk(root, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if entering {
+			switch n.(type) {
+			case *east.FootnoteLink:
+				links = append(links, n)
+			case *east.FootnoteBacklink:
+				backlinks = append(backlinks, n)
+			}
+		}
+		return gast.WalkContinue, nil
+	})
+	for _, n := range links {
+		marker := gast.NewString([]byte(fmt.Sprintf("[%d]", n.(*east.FootnoteLink).Index)))
+		n.Parent().ReplaceChild(n.Parent(), n, marker)
+	}
+	for _, n := range backlinks {
+		n.Parent().RemoveChild(n.Parent(), n)
+	}
+}
+
+type blockConverter struct {
+	source []byte
+	doc    *blocks.Document
+}
+
+func (c *blockConverter) convert(n gast.Node) {
+	switch node := n.(type) {
+	case *gast.Heading:
+		if text := c.inline(node); text != "" {
+			c.doc.Append(blocks.TypeHeader, blocks.Header{Text: text, Level: node.Level})
+		}
+	case *gast.Paragraph:
+		if img, ok := node.FirstChild().(*gast.Image); ok && node.ChildCount() == 1 && isHTTP(string(img.Destination)) {
+			image := blocks.Image{Caption: html.EscapeString(string(img.Text(c.source)))}
+			image.File.URL = string(img.Destination)
+			c.doc.Append(blocks.TypeImage, image)
+			return
+		}
+		if text := c.inline(node); text != "" {
+			c.doc.Append(blocks.TypeParagraph, blocks.Paragraph{Text: text})
+		}
+	case *gast.Blockquote:
+		if text := strings.Join(c.texts(node), "<br>"); text != "" {
+			c.doc.Append(blocks.TypeQuote, blocks.Quote{Text: text})
+		}
+	case *gast.List:
+		style := "unordered"
+		if node.IsOrdered() {
+			style = "ordered"
+		}
+		if items := c.texts(node); len(items) > 0 {
+			c.doc.Append(blocks.TypeList, blocks.List{Style: style, Items: items})
+		}
+	case *gast.FencedCodeBlock, *gast.CodeBlock:
+		code := blocks.Code{Code: strings.TrimRight(c.lines(node), "\n")}
+		if fenced, ok := node.(*gast.FencedCodeBlock); ok && codeLanguage.MatchString(string(fenced.Language(c.source))) {
+			code.Language = string(fenced.Language(c.source))
+		}
+		if code.Code != "" {
+			c.doc.Append(blocks.TypeCode, code)
+		}
+	case *east.Table:
+		for row := node.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, c.inline(cell))
+			}
+			c.doc.Append(blocks.TypeParagraph, blocks.Paragraph{Text: strings.Join(cells, " | ")})
+		}
+	case *east.FootnoteList:
+		if items := c.texts(node); len(items) > 0 {
+			c.doc.Append(blocks.TypeList, blocks.List{Style: "ordered", Items: items})
+		}
+	}
+}
+
+// texts flattens the blocks under n into one inline fragment per text
+// block, so nested lists and quoted paragraphs keep their words.
+func (c *blockConverter) texts(n gast.Node) []string {
+	var out []string
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.Kind() {
+		case gast.KindParagraph, gast.KindTextBlock, gast.KindHeading:
+			if text := c.inline(child); text != "" {
+				out = append(out, text)
+			}
+		case gast.KindFencedCodeBlock, gast.KindCodeBlock:
+			out = append(out, "<code>"+html.EscapeString(strings.TrimRight(c.lines(child), "\n"))+"</code>")
+		default:
+			out = append(out, c.texts(child)...)
+		}
+	}
+	return out
+}
+
+// inline renders the children of a text block to the inline HTML block
+// text holds.
+func (c *blockConverter) inline(n gast.Node) string {
+	var buf bytes.Buffer
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		_ = converter.Renderer().Render(&buf, c.source, child)
+	}
+	return strings.TrimSpace(blocks.SanitizeInline(buf.String()))
+}
+
+func (c *blockConverter) lines(n gast.Node) string {
+	var b strings.Builder
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		b.Write(line.Value(c.source))
+	}
+	return b.String()
+}
+
+func isHTTP(link string) bool {
+	u, err := url.ParseRequestURI(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,105 @@
+// Package markdown renders article text to HTML that is safe to embed in a
+// page as is.
+//
+// The syntax is CommonMark plus GFM tables, strikethrough and autolinks,
+// footnotes ([^1]), fenced code blocks with syntax highlighting and inline
+// spoilers (||text||). Raw HTML in the source is dropped, and the rendered
+// HTML is run through an allowlist sanitizer on top of that, so neither a
+// parser bug nor a crafted link can smuggle in a script.
+//
+// Highlighted code uses chroma CSS classes rather than inline styles; the
+// frontend ships the stylesheet.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var converter = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.Footnote,
+		highlighting.NewHighlighting(highlighting.WithFormatOptions(html.WithClasses(true))),
+		&spoilerExtension{},
+	),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// chroma token classes, footnote links and spoilers
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-z0-9-]+( [a-z0-9-]+)*$`)).OnElements("span", "pre", "code", "a", "div")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^fn(ref)?:\d+$`)).OnElements("sup", "li")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	return p
+}
+
+// Render converts Markdown source to sanitized HTML.
+func Render(source string) string {
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer can't fail, and the parser never errors
+	_ = converter.Convert([]byte(source), &buf)
+	return policy.Sanitize(buf.String())
+}
+
+// PlainText returns the text a reader sees, one block per line. Markup,
+// code blocks, images and footnotes are left out, and spoilers turn into an
+// ellipsis, so a feed card never gives away what a spoiler hides.
+func PlainText(source string) string {
+	src := []byte(source)
+	var out strings.Builder
+	collectText(converter.Parser().Parse(text.NewReader(src)), src, &out)
+
+	lines := strings.Split(out.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func collectText(n gast.Node, source []byte, out *strings.Builder) {
+	switch node := n.(type) {
+	case *gast.FencedCodeBlock, *gast.CodeBlock, *gast.HTMLBlock, *gast.RawHTML, *gast.Image,
+		*east.FootnoteLink, *east.Footnote, *east.FootnoteList:
+		return
+	case *Spoiler:
+		out.WriteString("…")
+		return
+	case *gast.Text:
+		out.Write(node.Segment.Value(source))
+		if node.SoftLineBreak() || node.HardLineBreak() {
+			out.WriteByte(' ')
+		}
+		return
+	case *gast.String:
+		out.Write(node.Value)
+		return
+	case *gast.AutoLink:
+		out.Write(node.Label(source))
+		return
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		collectText(child, source, out)
+	}
+	if n.Type() == gast.TypeBlock {
+		out.WriteByte('\n')
+	}
+}
@@ -0,0 +1,156 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/blocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "commonmark",
+			source: "# Заголовок\n\n**жирный** и *курсив*, `код`\n\n- один\n- два\n",
+			want:   []string{"<h1>Заголовок</h1>", "<strong>жирный</strong>", "<em>курсив</em>", "<code>код</code>", "<li>один</li>"},
+		},
+		{
+			name:   "table",
+			source: "| a | b |\n|:--|--:|\n| 1 | 2 |\n",
+			want:   []string{"<table>", `<th align="left">a</th>`, `<td align="right">2</td>`},
+		},
+		{
+			name:   "highlighted code",
+			source: "```go\nfunc main() {}\n```\n",
+			want:   []string{`<pre class="chroma">`, `<span class="kd">func</span>`},
+		},
+		{
+			name:   "footnote",
+			source: "Текст[^1]\n\n[^1]: Сноска.\n",
+			want:   []string{`<sup id="fnref:1"><a href="#fn:1"`, `<li id="fn:1">`, "Сноска."},
+		},
+		{
+			name:   "spoiler",
+			source: "Убийца — ||дворецкий||.",
+			want:   []string{`<span class="spoiler">дворецкий</span>`},
+		},
+		{
+			name:    "single pipe is text",
+			source:  "a | b",
+			want:    []string{"<p>a | b</p>"},
+			notWant: []string{"spoiler"},
+		},
+		{
+			name:    "raw html is dropped",
+			source:  "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			notWant: []string{"<script", "onerror", "alert"},
+		},
+		{
+			name:    "javascript link",
+			source:  "[нажми](javascript:alert(1))",
+			want:    []string{"нажми"},
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:    "inline style from code language",
+			source:  "```go\" style=\"color:red\nx\n```\n",
+			notWant: []string{"style="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.source)
+			for _, want := range tt.want {
+				assert.Contains(t, got, want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, got, notWant)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "plain text", source: "Просто текст.", want: "Просто текст."},
+		{name: "markup is stripped", source: "# Итоги\n\n**Рост** на [30%](https://example.com).", want: "Итоги\nРост на 30%."},
+		{name: "soft line breaks join", source: "Первая строка\nвторая строка", want: "Первая строка вторая строка"},
+		{name: "spoiler is hidden", source: "Убийца — ||дворецкий||.", want: "Убийца — …."},
+		{name: "code and footnotes are skipped", source: "Смотрите[^1]:\n\n```go\nfunc main() {}\n```\n\n[^1]: Сноска.\n", want: "Смотрите:"},
+		{name: "list items are lines", source: "- один\n- два\n", want: "один\nдва"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PlainText(tt.source))
+		})
+	}
+}
+
+func TestToBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "plain text",
+			source: "Первый абзац.\n\nВторой абзац.",
+			want:   `[{"type":"paragraph","data":{"text":"Первый абзац."}},{"type":"paragraph","data":{"text":"Второй абзац."}}]`,
+		},
+		{
+			name:   "heading and emphasis",
+			source: "## Итоги\n\nРост на **30%**",
+			want:   `[{"type":"header","data":{"text":"Итоги","level":2}},{"type":"paragraph","data":{"text":"Рост на <strong>30%</strong>"}}]`,
+		},
+		{
+			name:   "nested list is flattened",
+			source: "1. один\n2. два\n   - вложенный\n",
+			want:   `[{"type":"list","data":{"style":"ordered","items":["один","два","вложенный"]}}]`,
+		},
+		{
+			name:   "quote",
+			source: "> первая\n>\n> вторая",
+			want:   `[{"type":"quote","data":{"text":"первая<br>вторая"}}]`,
+		},
+		{
+			name:   "code with language",
+			source: "```go\nfunc main() {}\n```",
+			want:   `[{"type":"code","data":{"code":"func main() {}","language":"go"}}]`,
+		},
+		{
+			name:   "standalone image",
+			source: "![График](https://example.com/a.png)",
+			want:   `[{"type":"image","data":{"file":{"url":"https://example.com/a.png"},"caption":"График"}}]`,
+		},
+		{
+			name:   "footnotes",
+			source: "Текст[^1]\n\n[^1]: Сноска.",
+			want:   `[{"type":"paragraph","data":{"text":"Текст[1]"}},{"type":"list","data":{"style":"ordered","items":["Сноска."]}}]`,
+		},
+		{
+			name:   "raw html only",
+			source: "<div>текст</div>",
+			want:   `[{"type":"paragraph","data":{"text":"&lt;div&gt;текст&lt;/div&gt;"}}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := ToBlocks(tt.source)
+			assert.Equal(t, `{"version":1,"blocks":`+tt.want+`}`, doc.Marshal())
+			_, err := blocks.Parse([]byte(doc.Marshal()))
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindSpoiler is the node kind of ||spoiler|| text.
+var KindSpoiler = gast.NewNodeKind("Spoiler")
+
+// Spoiler is inline text hidden until the reader clicks it.
+type Spoiler struct {
+	gast.BaseInline
+}
+
+func (n *Spoiler) Kind() gast.NodeKind {
+	return KindSpoiler
+}
+
+func (n *Spoiler) Dump(source []byte, level int) {
+	gast.DumpHelper(n, source, level, nil, nil)
+}
+
+type spoilerDelimiterProcessor struct{}
+
+func (p *spoilerDelimiterProcessor) IsDelimiter(b byte) bool {
+	return b == '|'
+}
+
+func (p *spoilerDelimiterProcessor) CanOpenCloser(opener, closer *parser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+func (p *spoilerDelimiterProcessor) OnMatch(consumes int) gast.Node {
+	return &Spoiler{}
+}
+
+var defaultSpoilerDelimiterProcessor = &spoilerDelimiterProcessor{}
+
+// spoilerParser picks up exactly two pipes, so a lone | in text and table
+// cells stays what it was.
+type spoilerParser struct{}
+
+func (s *spoilerParser) Trigger() []byte {
+	return []byte{'|'}
+}
+
+func (s *spoilerParser) Parse(parent gast.Node, block text.Reader, pc parser.Context) gast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := parser.ScanDelimiter(line, before, 2, defaultSpoilerDelimiterProcessor)
+	if node == nil || node.OriginalLength != 2 || before == '|' {
+		return nil
+	}
+
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+func (s *spoilerParser) CloseBlock(parent gast.Node, pc parser.Context) {}
+
+type spoilerRenderer struct{}
+
+func (r *spoilerRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindSpoiler, r.renderSpoiler)
+}
+
+func (r *spoilerRenderer) renderSpoiler(w util.BufWriter, source []byte, n gast.Node, entering bool) (gast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(`<span class="spoiler">`)
+	} else {
+		_, _ = w.WriteString("</span>")
+	}
+	return gast.WalkContinue, nil
+}
+
+type spoilerExtension struct{}
+
+func (e *spoilerExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(&spoilerParser{}, 500),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&spoilerRenderer{}, 500),
+	))
+}
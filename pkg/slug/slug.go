@@ -0,0 +1,75 @@
+// Package slug turns titles into URL slugs: lowercase Latin letters and
+// digits separated by single hyphens. Cyrillic is transliterated the way
+// Russian URLs usually are ("щ" → "shch", "я" → "ya"); accents are dropped
+// from other Latin letters, and any other character separates words.
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength bounds a slug; longer ones are cut at a word.
+const MaxLength = 80
+
+// Fallback is the slug of a title with nothing to transliterate.
+const Fallback = "article"
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Ukrainian and Belarusian
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Make returns the slug of the title.
+func Make(title string) string {
+	var b strings.Builder
+	hyphen := false
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteString(s)
+	}
+
+	for _, r := range norm.NFC.String(strings.ToLower(title)) {
+		if latin, ok := cyrillic[r]; ok {
+			write(latin)
+			continue
+		}
+		// decomposing "é" leaves "e" and a combining accent to skip
+		for _, d := range norm.NFKD.String(string(r)) {
+			switch {
+			case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				write(string(d))
+			case unicode.Is(unicode.Mn, d):
+			case d == '\'' || d == '’':
+				// "don't" is one word
+			default:
+				hyphen = true
+			}
+		}
+	}
+
+	s := b.String()
+	if len(s) > MaxLength {
+		s = s[:MaxLength]
+		if cut := strings.LastIndexByte(s, '-'); cut > 0 {
+			s = s[:cut]
+		}
+	}
+	if s == "" {
+		return Fallback
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "russian", title: "ИИ в 2025: Как нейросети меняют бизнес-процессы", want: "ii-v-2025-kak-neyroseti-menyayut-biznes-protsessy"},
+		{name: "mixed scripts and symbols", title: "Как российский стартап привлёк $10M на рынке SaaS", want: "kak-rossiyskiy-startap-privlyok-10m-na-rynke-saas"},
+		{name: "percent", title: "Почему 80% стартапов терпят неудачу", want: "pochemu-80-startapov-terpyat-neudachu"},
+		{name: "hard letters", title: "Щука, ёж и объём", want: "shchuka-yozh-i-obyom"},
+		{name: "decomposed letters", title: "чай и ёж", want: "chay-i-yozh"},
+		{name: "english apostrophe", title: "Don't Panic!", want: "dont-panic"},
+		{name: "accents", title: "Café déjà vu", want: "cafe-deja-vu"},
+		{name: "surrounding punctuation", title: "  «Итоги» — года... ", want: "itogi-goda"},
+		{name: "nothing to keep", title: "🔥🔥🔥", want: Fallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Make(tt.title))
+		})
+	}
+}
+
+func TestMakeCutsLongTitlesAtAWord(t *testing.T) {
+	got := Make(strings.Repeat("Экспериментальный заголовок ", 10))
+
+	assert.LessOrEqual(t, len(got), MaxLength)
+	assert.True(t, strings.HasSuffix(got, "eksperimentalnyy") || strings.HasSuffix(got, "zagolovok"), got)
+}
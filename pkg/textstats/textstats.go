@@ -0,0 +1,172 @@
+// Package textstats splits Russian and English text into words and
+// sentences to count words, estimate reading time and cut excerpts.
+//
+// Words are the ones package wordbreak finds. Sentences end at ., !, ? and
+// …, but not after common abbreviations and initials ("т. е.", "Mr.",
+// "А. С. Пушкин"), and not when the next word starts with a lowercase
+// letter. A line break always ends a sentence, so headings without a full
+// stop stand on their own.
+package textstats
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/wordbreak"
+)
+
+// Silent reading speeds in words per minute for non-fiction, from the
+// IReST study (Trauzettel-Klosinski et al., 2012). Russian words are longer
+// on average, so they read slower.
+const (
+	russianWPM = 184
+	englishWPM = 228
+)
+
+type Stats struct {
+	Words          int
+	ReadingMinutes int
+}
+
+// Count counts the words of the text and estimates how many minutes it
+// takes to read, rounding up. Empty text takes zero minutes.
+func Count(text string) Stats {
+	var russian, other int
+	for _, word := range wordbreak.Words(text) {
+		if strings.IndexFunc(word, isCyrillic) >= 0 {
+			russian++
+		} else {
+			other++
+		}
+	}
+	minutes := float64(russian)/russianWPM + float64(other)/englishWPM
+	return Stats{Words: russian + other, ReadingMinutes: int(math.Ceil(minutes))}
+}
+
+// Sentences returns the sentences of the text with their whitespace
+// collapsed.
+func Sentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		start := 0
+		for i := 0; i < len(runes); i++ {
+			if !isTerminator(runes[i]) {
+				continue
+			}
+			end := i + 1
+			for end < len(runes) && (isTerminator(runes[end]) || isCloser(runes[end])) {
+				end++
+			}
+			if endsSentence(runes, i, end) {
+				sentences = appendSentence(sentences, runes[start:end])
+				start = end
+			}
+			i = end - 1
+		}
+		sentences = appendSentence(sentences, runes[start:])
+	}
+	return sentences
+}
+
+// Excerpt returns as many whole sentences from the start of the text as fit
+// in limit characters. When even the first sentence is too long, it is cut
+// at a word boundary and ends with an ellipsis.
+func Excerpt(text string, limit int) string {
+	var b strings.Builder
+	length := 0
+	for _, sentence := range Sentences(text) {
+		n := utf8.RuneCountInString(sentence)
+		if length > 0 {
+			n++
+		}
+		if length+n > limit {
+			break
+		}
+		if length > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(sentence)
+		length += n
+	}
+	if length == 0 {
+		return truncate(strings.Join(strings.Fields(text), " "), limit)
+	}
+	return b.String()
+}
+
+func appendSentence(sentences []string, runes []rune) []string {
+	if sentence := strings.Join(strings.Fields(string(runes)), " "); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// abbreviations rarely end a sentence, so a full stop after them doesn't
+// either. Dotted ones are stored without the final dot, as wordbreak.Words
+// returns them.
+var abbreviations = map[string]bool{
+	// Russian
+	"т.е": true, "т.к": true, "т.н": true, "т.ч": true, "т": true, "им": true, "ул": true, "пр": true,
+	"проф": true, "акад": true, "доц": true, "см": true, "рис": true, "стр": true, "напр": true, "ср": true,
+	// English
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true, "jr": true, "sr": true,
+	"vs": true, "e.g": true, "i.e": true, "cf": true, "fig": true, "approx": true,
+}
+
+// endsSentence decides whether the terminators at runes[i:end] end a
+// sentence.
+func endsSentence(runes []rune, i, end int) bool {
+	next := end
+	for next < len(runes) && unicode.IsSpace(runes[next]) {
+		next++
+	}
+	if next == len(runes) {
+		return true
+	}
+	if next == end || unicode.IsLower(runes[next]) {
+		return false
+	}
+	if runes[i] != '.' || end-i > 1 {
+		return true
+	}
+
+	// the word right before the full stop
+	word := runes[wordbreak.Start(runes, i):i]
+	if len(word) == 1 && unicode.IsUpper(word[0]) {
+		return false // an initial
+	}
+	return !abbreviations[strings.ToLower(string(word))]
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)[:limit]
+	// step back to the last space unless the first word alone is too long
+	if cut := strings.LastIndexFunc(string(runes), unicode.IsSpace); cut > 0 {
+		return strings.TrimRightFunc(string(runes)[:cut], unicode.IsPunct) + "…"
+	}
+	return string(runes) + "…"
+}
+
+func isCyrillic(r rune) bool {
+	return unicode.Is(unicode.Cyrillic, r)
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// isCloser reports whether r closes a quote or bracket, which belongs to
+// the sentence it follows.
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '»', '”', '’', ')', ']':
+		return true
+	}
+	return false
+}
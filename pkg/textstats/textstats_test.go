@@ -0,0 +1,78 @@
+package textstats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "simple", text: "Первое. Второе! Третье?", want: []string{"Первое.", "Второе!", "Третье?"}},
+		{name: "no final stop", text: "Первое. Второе", want: []string{"Первое.", "Второе"}},
+		{name: "line breaks", text: "Заголовок\nТекст статьи.", want: []string{"Заголовок", "Текст статьи."}},
+		{name: "russian abbreviations", text: "Это важно, т.е. нужно. См. рис. 2 ниже. Конец.", want: []string{"Это важно, т.е. нужно.", "См. рис. 2 ниже.", "Конец."}},
+		{name: "english abbreviations", text: "Mr. Smith met Dr. Brown, e.g. Today. It rained.", want: []string{"Mr. Smith met Dr. Brown, e.g. Today.", "It rained."}},
+		{name: "initials", text: "А. С. Пушкин родился в Москве. Это известно.", want: []string{"А. С. Пушкин родился в Москве.", "Это известно."}},
+		{name: "lowercase continues", text: "Он сказал... и ушёл. Всё.", want: []string{"Он сказал... и ушёл.", "Всё."}},
+		{name: "closing quotes", text: "Он спросил: «Зачем?» Никто не ответил.", want: []string{"Он спросил: «Зачем?»", "Никто не ответил."}},
+		{name: "numbers and domains", text: "Рост 3.5% на example.com. Дальше.", want: []string{"Рост 3.5% на example.com.", "Дальше."}},
+		{name: "dialogue dash", text: "Вопрос?! — Ответ.", want: []string{"Вопрос?!", "— Ответ."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sentences(tt.text))
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "fits", text: "Первое. Второе.", limit: 100, want: "Первое. Второе."},
+		{name: "whole sentences only", text: "Первое предложение. Второе предложение.", limit: 30, want: "Первое предложение."},
+		{name: "exact fit", text: "Раз. Два.", limit: 9, want: "Раз. Два."},
+		{name: "lines become sentences", text: "Заголовок\nТекст.", limit: 100, want: "Заголовок Текст."},
+		{name: "long first sentence", text: "Искусственный интеллект стал частью бизнеса. Дальше.", limit: 30, want: "Искусственный интеллект стал…"},
+		{name: "long first word", text: "Сверхдлинноеслово", limit: 5, want: "Сверх…"},
+		{name: "empty", text: "", limit: 10, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Excerpt(tt.text, tt.limit))
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Stats
+	}{
+		{name: "empty", text: "", want: Stats{}},
+		{name: "short", text: "Всего три слова", want: Stats{Words: 3, ReadingMinutes: 1}},
+		{name: "russian minute", text: strings.Repeat("слово ", russianWPM), want: Stats{Words: russianWPM, ReadingMinutes: 1}},
+		{name: "russian reads slower", text: strings.Repeat("слово ", englishWPM), want: Stats{Words: englishWPM, ReadingMinutes: 2}},
+		{name: "english minute", text: strings.Repeat("word ", englishWPM), want: Stats{Words: englishWPM, ReadingMinutes: 1}},
+		{name: "mixed", text: strings.Repeat("слово ", russianWPM) + strings.Repeat("word ", englishWPM), want: Stats{Words: russianWPM + englishWPM, ReadingMinutes: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Count(tt.text))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package wordbreak finds the words of Russian and English text. Word
+// counts, excerpts, muted keywords and revision diffs all split text here,
+// so they agree on what a word is.
+//
+// A word is a run of letters, digits and combining marks, so Cyrillic text
+// splits the same way as Latin. Hyphens and apostrophes inside a word keep
+// it whole ("из-за", "don't"), as do dots in dotted abbreviations and
+// domains ("т.е.", "e.g.", "example.com") and decimal separators ("3.14",
+// "3,14").
+package wordbreak
+
+import "unicode"
+
+// Words returns the words of the text in order.
+func Words(text string) []string {
+	runes := []rune(text)
+	var words []string
+	for start := 0; start < len(runes); {
+		if !IsWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := End(runes, start)
+		words = append(words, string(runes[start:end]))
+		start = end
+	}
+	return words
+}
+
+// End returns the index just past the word that starts at runes[start].
+func End(runes []rune, start int) int {
+	end := start + 1
+	for end < len(runes) && (IsWordRune(runes[end]) || isJoiner(runes, end)) {
+		end++
+	}
+	return end
+}
+
+// Start returns the index of the first rune of the word that ends just
+// before runes[end], or end if no word ends there.
+func Start(runes []rune, end int) int {
+	start := end
+	for start > 0 && (IsWordRune(runes[start-1]) || isJoiner(runes, start-1)) {
+		start--
+	}
+	return start
+}
+
+func IsWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// isJoiner reports whether runes[i] joins the word runes around it into one
+// word.
+func isJoiner(runes []rune, i int) bool {
+	if i == 0 || i+1 >= len(runes) {
+		return false
+	}
+	before, after := runes[i-1], runes[i+1]
+	switch runes[i] {
+	case '-', '\'', '’', '‐':
+		return IsWordRune(before) && IsWordRune(after)
+	case '.':
+		return (unicode.IsLetter(before) && unicode.IsLetter(after)) || (unicode.IsDigit(before) && unicode.IsDigit(after))
+	case ',':
+		return unicode.IsDigit(before) && unicode.IsDigit(after)
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package wordbreak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "punctuation and dashes", text: "ИИ — это, конечно, тренд!", want: []string{"ИИ", "это", "конечно", "тренд"}},
+		{name: "hyphenated", text: "из-за бизнес-процессов", want: []string{"из-за", "бизнес-процессов"}},
+		{name: "hyphen at the edge", text: "-раз два-", want: []string{"раз", "два"}},
+		{name: "apostrophes", text: "don't stop, it’s fine", want: []string{"don't", "stop", "it’s", "fine"}},
+		{name: "dotted abbreviations", text: "т.е. e.g. example.com", want: []string{"т.е", "e.g", "example.com"}},
+		{name: "numbers", text: "на 30% и 3,14 или 2.5", want: []string{"на", "30", "и", "3,14", "или", "2.5"}},
+		{name: "mixed scripts", text: "SaaS-платформу за $10M", want: []string{"SaaS-платформу", "за", "10M"}},
+		{name: "combining mark", text: "ча\u0438\u0306 готов", want: []string{"ча\u0438\u0306", "готов"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Words(tt.text))
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "word", text: "Привет мир", want: "мир"},
+		{name: "hyphenated", text: "это из-за", want: "из-за"},
+		{name: "abbreviation", text: "см. т.е", want: "т.е"},
+		{name: "no word", text: "мир, ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runes := []rune(tt.text)
+			assert.Equal(t, tt.want, string(runes[Start(runes, len(runes)):]))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package worddiff computes word-level differences between two texts.
+//
+// Texts are split into words, runs of whitespace and single punctuation
+// marks. Words are the ones package wordbreak finds, so "из-за" or "3,14"
+// changes as one word, as it is counted. Both texts are NFC-normalized
+// first, so "й" typed as one code point or as "и" plus a breve compares
+// equal.
+package worddiff
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/go-park-mail-ru/2025_2_MindLeak/pkg/wordbreak"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// Change is a run of consecutive tokens with the same operation.
+type Change struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Tokenize splits the text into words, whitespace runs and punctuation
+// marks. Joining the tokens gives back the normalized text.
+func Tokenize(text string) []string {
+	runes := []rune(norm.NFC.String(text))
+	tokens := make([]string, 0, len(runes)/4)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		switch {
+		case wordbreak.IsWordRune(runes[start]):
+			end = wordbreak.End(runes, start)
+		case unicode.IsSpace(runes[start]):
+			for end < len(runes) && unicode.IsSpace(runes[end]) {
+				end++
+			}
+		}
+		tokens = append(tokens, string(runes[start:end]))
+		start = end
+	}
+	return tokens
+}
+
+// Diff returns the changes that turn a into b. Equal runs are included, so
+// the changes cover both texts completely.
+func Diff(a, b string) []Change {
+	x, y := Tokenize(a), Tokenize(b)
+
+	// common prefix and suffix are cheap to strip and usually most of an edit
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var out builder
+	out.add(OpEqual, x[:prefix]...)
+	for _, e := range myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]) {
+		out.add(e.op, e.token)
+	}
+	out.add(OpEqual, x[len(x)-suffix:]...)
+	return out.finish()
+}
+
+type edit struct {
+	op    Op
+	token string
+}
+
+// maxEditDistance bounds the work and memory of myers. Texts that differ
+// more than this are reported as deleted and inserted wholesale.
+const maxEditDistance = 1000
+
+// myers is the O((N+M)D) shortest edit script algorithm.
+func myers(x, y []string) []edit {
+	n, m := len(x), len(y)
+	if n+m == 0 {
+		return nil
+	}
+	limit := min(n+m, maxEditDistance)
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] keeps v[-d..d] as it was before step d
+	trace := make([][]int, 0, limit+1)
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				return backtrack(x, y, trace)
+			}
+		}
+	}
+
+	edits := make([]edit, 0, n+m)
+	for _, token := range x {
+		edits = append(edits, edit{OpDelete, token})
+	}
+	for _, token := range y {
+		edits = append(edits, edit{OpInsert, token})
+	}
+	return edits
+}
+
+func backtrack(x, y []string, trace [][]int) []edit {
+	i, j := len(x), len(y)
+	edits := make([]edit, 0, i+j)
+	for d := len(trace) - 1; d > 0; d-- {
+		// trace[d] holds v[-d..d] from before step d, i.e. the result of step d-1
+		v := func(k int) int { return trace[d][k+d] }
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := v(prevK)
+		prevJ := prevI - prevK
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			edits = append(edits, edit{OpEqual, x[i]})
+		}
+		if i == prevI {
+			j--
+			edits = append(edits, edit{OpInsert, y[j]})
+		} else {
+			i--
+			edits = append(edits, edit{OpDelete, x[i]})
+		}
+	}
+	for i > 0 {
+		i--
+		edits = append(edits, edit{OpEqual, x[i]})
+	}
+
+	for l, r := 0, len(edits)-1; l < r; l, r = l+1, r-1 {
+		edits[l], edits[r] = edits[r], edits[l]
+	}
+	return edits
+}
+
+// builder merges consecutive tokens with the same operation.
+type builder struct {
+	changes []Change
+	op      Op
+	text    strings.Builder
+}
+
+func (b *builder) add(op Op, tokens ...string) {
+	for _, token := range tokens {
+		if op != b.op {
+			b.flush()
+			b.op = op
+		}
+		b.text.WriteString(token)
+	}
+}
+
+func (b *builder) flush() {
+	if b.text.Len() > 0 {
+		b.changes = append(b.changes, Change{Op: b.op, Text: b.text.String()})
+		b.text.Reset()
+	}
+}
+
+func (b *builder) finish() []Change {
+	b.flush()
+	if b.changes == nil {
+		return []Change{}
+	}
+	return b.changes
+}
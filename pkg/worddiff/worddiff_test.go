@@ -0,0 +1,115 @@
+package worddiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: []string{}},
+		{name: "cyrillic words", text: "Привет,  мир!", want: []string{"Привет", ",", "  ", "мир", "!"}},
+		{name: "hyphenated word", text: "из-за бизнес-процессов", want: []string{"из-за", " ", "бизнес-процессов"}},
+		{name: "dash between words", text: "ИИ - это", want: []string{"ИИ", " ", "-", " ", "это"}},
+		{name: "apostrophe", text: "don't stop", want: []string{"don't", " ", "stop"}},
+		{name: "digits and percent", text: "на 30%", want: []string{"на", " ", "30", "%"}},
+		{name: "decimals and domains", text: "3,14 на example.com.", want: []string{"3,14", " ", "на", " ", "example.com", "."}},
+		{name: "decomposed letter", text: "ча\u0438\u0306", want: []string{"ча\u0439"}},
+		{name: "mixed scripts", text: "SaaS-платформу", want: []string{"SaaS-платформу"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.text)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Change
+	}{
+		{
+			name: "identical",
+			a:    "Один два три",
+			b:    "Один два три",
+			want: []Change{{OpEqual, "Один два три"}},
+		},
+		{
+			name: "both empty",
+			want: []Change{},
+		},
+		{
+			name: "replaced word",
+			a:    "Стартап привлёк $10M",
+			b:    "Стартап привлёк $12M",
+			want: []Change{{OpEqual, "Стартап привлёк $"}, {OpDelete, "10M"}, {OpInsert, "12M"}},
+		},
+		{
+			name: "inserted words",
+			a:    "Как мы увеличили конверсию",
+			b:    "Как мы быстро увеличили конверсию",
+			want: []Change{{OpEqual, "Как мы "}, {OpInsert, "быстро "}, {OpEqual, "увеличили конверсию"}},
+		},
+		{
+			name: "deleted words",
+			a:    "Тренды контент-маркетинга в 2025 году",
+			b:    "Тренды контент-маркетинга",
+			want: []Change{{OpEqual, "Тренды контент-маркетинга"}, {OpDelete, " в 2025 году"}},
+		},
+		{
+			name: "changes in the middle",
+			a:    "раз два три четыре пять",
+			b:    "раз три два четыре шесть",
+			want: []Change{
+				{OpEqual, "раз "}, {OpDelete, "два "}, {OpEqual, "три "}, {OpInsert, "два "},
+				{OpEqual, "четыре "}, {OpDelete, "пять"}, {OpInsert, "шесть"},
+			},
+		},
+		{
+			name: "normalization is not a change",
+			a:    "ча\u0439",
+			b:    "ча\u0438\u0306",
+			want: []Change{{OpEqual, "чай"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.a, tt.b))
+		})
+	}
+}
+
+func TestDiffCoversBothTexts(t *testing.T) {
+	a := strings.Repeat("старый текст статьи. ", 50)
+	b := strings.Repeat("новый текст заметки! ", 60)
+
+	var before, after strings.Builder
+	for _, c := range Diff(a, b) {
+		if c.Op != OpInsert {
+			before.WriteString(c.Text)
+		}
+		if c.Op != OpDelete {
+			after.WriteString(c.Text)
+		}
+	}
+	assert.Equal(t, a, before.String())
+	assert.Equal(t, b, after.String())
+}
+
+func TestDiffFallsBackForLargeRewrites(t *testing.T) {
+	a := strings.Repeat("а ", maxEditDistance)
+	b := strings.Repeat("б ", maxEditDistance)
+
+	assert.Equal(t, []Change{{OpDelete, strings.TrimSuffix(a, " ")}, {OpInsert, strings.TrimSuffix(b, " ")}, {OpEqual, " "}}, Diff(a, b))
+}